@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	config "github.com/dynamicgo/go-config"
+	"github.com/dynamicgo/orm"
+	"github.com/dynamicgo/slf4go"
+	"github.com/go-xorm/xorm"
+	sensors "github.com/laplacenetwork/eth-sensors"
+)
+
+// Event the persisted order status changed event, the event id is the stream cursor
+type Event struct {
+	ID         int64     `xorm:"pk autoincr"`
+	Key        string    `xorm:"index"` // watcher key
+	OrderID    string    `xorm:"index"`
+	Status     string    `xorm:""`
+	Payload    string    `xorm:"text"` // json encoding order
+	CreateTime time.Time `xorm:""`     // stamped by the sensor clock
+}
+
+// TableName .
+func (table *Event) TableName() string {
+	return "eth_sensors_event"
+}
+
+// Order decode event payload
+func (table *Event) Order() (*sensors.Order, error) {
+	var order sensors.Order
+
+	if err := json.Unmarshal([]byte(table.Payload), &order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+type subscriber struct {
+	key string
+	C   chan *Event
+}
+
+// Broker the sensors notifier which persist order events and fan out them to the stream subscribers
+type Broker struct {
+	sync.RWMutex
+	slf4go.Logger
+	engine      *xorm.Engine
+	clock       sensors.Clock
+	subscribers map[*subscriber]bool
+	buffer      int
+	// serialize the event insert and publish, the chains notify concurrently and the stream subscribers
+	// drop the events not after their cursor
+	sequence sync.Mutex
+}
+
+// NewBroker create broker persisting events in the sensor database, config is the notifier config,
+// clock is the sensor clock stamping the events
+func NewBroker(engine *xorm.Engine, clock sensors.Clock, config config.Config) *Broker {
+	return NewBrokerWithEngine(engine, clock, config.Get("buffer").Int(100))
+}
+
+// NewBrokerWithEngine create broker with exists database engine
+func NewBrokerWithEngine(engine *xorm.Engine, clock sensors.Clock, buffer int) *Broker {
+	return &Broker{
+		Logger:      slf4go.Get("api-broker"),
+		engine:      engine,
+		clock:       clock,
+		subscribers: make(map[*subscriber]bool),
+		buffer:      buffer,
+	}
+}
+
+// Notify implement sensors.Notifier
+func (broker *Broker) Notify(receiver *sensors.Watcher, order *sensors.Order) error {
+
+	payload, err := json.Marshal(order)
+
+	if err != nil {
+		return err
+	}
+
+	event := &Event{
+		Key:        receiver.Key,
+		OrderID:    order.ID,
+		Status:     string(order.Status),
+		Payload:    string(payload),
+		CreateTime: broker.clock.Now(),
+	}
+
+	broker.sequence.Lock()
+	defer broker.sequence.Unlock()
+
+	if _, err := broker.engine.InsertOne(event); err != nil {
+		broker.ErrorF("save order %s event for watcher %s err: %s", order.ID, receiver.Key, err)
+		return err
+	}
+
+	broker.publish(event)
+
+	return nil
+}
+
+func (broker *Broker) publish(event *Event) {
+	broker.Lock()
+	defer broker.Unlock()
+
+	for sub := range broker.subscribers {
+		if sub.key != event.Key {
+			continue
+		}
+
+		select {
+		case sub.C <- event:
+		default:
+			// slow subscriber, close it and let the client resume from cursor
+			broker.WarnF("drop slow subscriber of watcher %s", sub.key)
+			delete(broker.subscribers, sub)
+			close(sub.C)
+		}
+	}
+}
+
+func (broker *Broker) subscribe(key string) *subscriber {
+	broker.Lock()
+	defer broker.Unlock()
+
+	sub := &subscriber{
+		key: key,
+		C:   make(chan *Event, broker.buffer),
+	}
+
+	broker.subscribers[sub] = true
+
+	return sub
+}
+
+func (broker *Broker) unsubscribe(sub *subscriber) {
+	broker.Lock()
+	defer broker.Unlock()
+
+	if broker.subscribers[sub] {
+		delete(broker.subscribers, sub)
+		close(sub.C)
+	}
+}
+
+// Since list watcher events after cursor
+func (broker *Broker) Since(key string, cursor int64, limit int) ([]*Event, error) {
+	events := make([]*Event, 0)
+
+	err := broker.engine.
+		Where(`"key" = ? and "i_d" > ?`, key, cursor).
+		Asc("i_d").
+		Limit(limit).
+		Find(&events)
+
+	return events, err
+}
+
+func init() {
+	orm.RegisterWithName("eth-sensors-api", func() []interface{} {
+		return []interface{}{
+			new(Event),
+		}
+	})
+}
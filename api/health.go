@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	config "github.com/dynamicgo/go-config"
+	sensors "github.com/laplacenetwork/eth-sensors"
+)
+
+// Health the sensor liveness and readiness http handlers
+type Health struct {
+	sensor   sensors.Sensor
+	clock    sensors.Clock // the sensor clock, the stall is measured by it
+	maxLag   int64         // readiness is false if the sensor is more than maxLag blocks behind chain head
+	maxStall time.Duration // liveness is false if no block processed successfully in maxStall
+}
+
+// NewHealth create health handlers with config
+func NewHealth(sensor sensors.Sensor, clock sensors.Clock, config config.Config) *Health {
+	return &Health{
+		sensor:   sensor,
+		clock:    clock,
+		maxLag:   int64(config.Get("health", "maxlag").Int(10)),
+		maxStall: config.Get("health", "stall").Duration(5 * time.Minute),
+	}
+}
+
+// Register register liveness handler on /healthz and readiness handler on /readyz
+func (health *Health) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", health.Live)
+	mux.HandleFunc("/readyz", health.Ready)
+}
+
+// Live liveness handler, false if any chain stalled on the leader replica
+func (health *Health) Live(w http.ResponseWriter, r *http.Request) {
+	statuses, err := health.sensor.Status()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	ok := true
+
+	for _, status := range statuses {
+		// the follower processes no block
+		if !status.Leader {
+			continue
+		}
+
+		if !status.LastProcessed.IsZero() && health.clock.Now().Sub(status.LastProcessed) > health.maxStall {
+			ok = false
+		}
+	}
+
+	health.write(w, ok, statuses)
+}
+
+// Ready readiness handler, false if any chain is not ready on the leader replica,
+// the follower is ready to serve the read apis
+func (health *Health) Ready(w http.ResponseWriter, r *http.Request) {
+	statuses, err := health.sensor.Status()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	ok := true
+
+	for _, status := range statuses {
+		if !status.Leader {
+			continue
+		}
+
+		if status.LastProcessed.IsZero() || status.Lag > health.maxLag || status.NotifierFailing {
+			ok = false
+		}
+	}
+
+	health.write(w, ok, statuses)
+}
+
+func (health *Health) write(w http.ResponseWriter, ok bool, statuses []*sensors.SyncStatus) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	json.NewEncoder(w).Encode(statuses)
+}
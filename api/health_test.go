@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/laplacenetwork/eth-sensors/sensorstest"
+	"github.com/stretchr/testify/require"
+)
+
+// statusSensor the fake sensor reporting statuses
+type statusSensor struct {
+	sensors.Sensor
+	statuses []*sensors.SyncStatus
+}
+
+func (sensor *statusSensor) Status() ([]*sensors.SyncStatus, error) {
+	return sensor.statuses, nil
+}
+
+func TestHealth(t *testing.T) {
+	clock := sensorstest.NewClock(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	status := &sensors.SyncStatus{ChainID: 1, Leader: true}
+
+	health := &Health{
+		sensor:   &statusSensor{statuses: []*sensors.SyncStatus{status}},
+		clock:    clock,
+		maxLag:   10,
+		maxStall: 5 * time.Minute,
+	}
+
+	mux := http.NewServeMux()
+
+	health.Register(mux)
+
+	get := func(path string) int {
+		recorder := httptest.NewRecorder()
+
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return recorder.Code
+	}
+
+	// started without processed block
+	require.Equal(t, http.StatusOK, get("/healthz"))
+	require.Equal(t, http.StatusServiceUnavailable, get("/readyz"))
+
+	status.LastProcessed = clock.Now()
+	status.Lag = 3
+
+	require.Equal(t, http.StatusOK, get("/healthz"))
+	require.Equal(t, http.StatusOK, get("/readyz"))
+
+	status.Lag = 11
+
+	require.Equal(t, http.StatusServiceUnavailable, get("/readyz"))
+
+	status.Lag = 0
+	status.NotifierFailing = true
+
+	require.Equal(t, http.StatusServiceUnavailable, get("/readyz"))
+
+	status.NotifierFailing = false
+
+	// stalled by the sensor clock
+	clock.Advance(6 * time.Minute)
+
+	require.Equal(t, http.StatusServiceUnavailable, get("/healthz"))
+	require.Equal(t, http.StatusOK, get("/readyz"))
+
+	// the follower serves the read apis without processing blocks
+	status.Leader = false
+	status.LastProcessed = time.Time{}
+	status.Lag = 100
+
+	require.Equal(t, http.StatusOK, get("/healthz"))
+	require.Equal(t, http.StatusOK, get("/readyz"))
+}
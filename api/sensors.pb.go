@@ -0,0 +1,1725 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: sensors.proto
+
+package api
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Watcher struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Key            string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Address        string                 `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	Erc20          bool                   `protobuf:"varint,5,opt,name=erc20,proto3" json:"erc20,omitempty"`
+	ChainId        int64                  `protobuf:"varint,6,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Confirmed      int64                  `protobuf:"varint,7,opt,name=confirmed,proto3" json:"confirmed,omitempty"`
+	Timeout        int64                  `protobuf:"varint,8,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	PendingTimeout int64                  `protobuf:"varint,9,opt,name=pending_timeout,json=pendingTimeout,proto3" json:"pending_timeout,omitempty"`
+	ConfirmTimeout int64                  `protobuf:"varint,10,opt,name=confirm_timeout,json=confirmTimeout,proto3" json:"confirm_timeout,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Watcher) Reset() {
+	*x = Watcher{}
+	mi := &file_sensors_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Watcher) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Watcher) ProtoMessage() {}
+
+func (x *Watcher) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Watcher.ProtoReflect.Descriptor instead.
+func (*Watcher) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Watcher) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Watcher) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Watcher) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Watcher) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Watcher) GetErc20() bool {
+	if x != nil {
+		return x.Erc20
+	}
+	return false
+}
+
+func (x *Watcher) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *Watcher) GetConfirmed() int64 {
+	if x != nil {
+		return x.Confirmed
+	}
+	return 0
+}
+
+func (x *Watcher) GetTimeout() int64 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *Watcher) GetPendingTimeout() int64 {
+	if x != nil {
+		return x.PendingTimeout
+	}
+	return 0
+}
+
+func (x *Watcher) GetConfirmTimeout() int64 {
+	if x != nil {
+		return x.ConfirmTimeout
+	}
+	return 0
+}
+
+type Order struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Tx             string                 `protobuf:"bytes,2,opt,name=tx,proto3" json:"tx,omitempty"`
+	PendingBlock   int64                  `protobuf:"varint,3,opt,name=pending_block,json=pendingBlock,proto3" json:"pending_block,omitempty"`
+	CommitBlock    int64                  `protobuf:"varint,4,opt,name=commit_block,json=commitBlock,proto3" json:"commit_block,omitempty"`
+	ConfirmBlock   int64                  `protobuf:"varint,5,opt,name=confirm_block,json=confirmBlock,proto3" json:"confirm_block,omitempty"`
+	Status         string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	CreateTime     int64                  `protobuf:"varint,7,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	PendingTime    int64                  `protobuf:"varint,8,opt,name=pending_time,json=pendingTime,proto3" json:"pending_time,omitempty"`
+	CommitTime     int64                  `protobuf:"varint,9,opt,name=commit_time,json=commitTime,proto3" json:"commit_time,omitempty"`
+	ConfirmTime    int64                  `protobuf:"varint,10,opt,name=confirm_time,json=confirmTime,proto3" json:"confirm_time,omitempty"`
+	From           string                 `protobuf:"bytes,11,opt,name=from,proto3" json:"from,omitempty"`
+	To             string                 `protobuf:"bytes,12,opt,name=to,proto3" json:"to,omitempty"`
+	Value          string                 `protobuf:"bytes,13,opt,name=value,proto3" json:"value,omitempty"`
+	Code           string                 `protobuf:"bytes,14,opt,name=code,proto3" json:"code,omitempty"`
+	GasLimits      string                 `protobuf:"bytes,15,opt,name=gas_limits,json=gasLimits,proto3" json:"gas_limits,omitempty"`
+	GasPrice       string                 `protobuf:"bytes,16,opt,name=gas_price,json=gasPrice,proto3" json:"gas_price,omitempty"`
+	ChainId        int64                  `protobuf:"varint,17,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	ConfirmDepth   int64                  `protobuf:"varint,18,opt,name=confirm_depth,json=confirmDepth,proto3" json:"confirm_depth,omitempty"`
+	TimeoutDepth   int64                  `protobuf:"varint,19,opt,name=timeout_depth,json=timeoutDepth,proto3" json:"timeout_depth,omitempty"`
+	PendingTimeout int64                  `protobuf:"varint,20,opt,name=pending_timeout,json=pendingTimeout,proto3" json:"pending_timeout,omitempty"`
+	ConfirmTimeout int64                  `protobuf:"varint,21,opt,name=confirm_timeout,json=confirmTimeout,proto3" json:"confirm_timeout,omitempty"`
+	TimeoutReason  string                 `protobuf:"bytes,22,opt,name=timeout_reason,json=timeoutReason,proto3" json:"timeout_reason,omitempty"`
+	FailureReason  string                 `protobuf:"bytes,23,opt,name=failure_reason,json=failureReason,proto3" json:"failure_reason,omitempty"`
+	RevertReason   string                 `protobuf:"bytes,24,opt,name=revert_reason,json=revertReason,proto3" json:"revert_reason,omitempty"`
+	Version        int64                  `protobuf:"varint,25,opt,name=version,proto3" json:"version,omitempty"`
+	Token          string                 `protobuf:"bytes,26,opt,name=token,proto3" json:"token,omitempty"`
+	Symbol         string                 `protobuf:"bytes,27,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Amount         string                 `protobuf:"bytes,28,opt,name=amount,proto3" json:"amount,omitempty"`
+	FiatValue      string                 `protobuf:"bytes,29,opt,name=fiat_value,json=fiatValue,proto3" json:"fiat_value,omitempty"`
+	FiatCurrency   string                 `protobuf:"bytes,30,opt,name=fiat_currency,json=fiatCurrency,proto3" json:"fiat_currency,omitempty"`
+	Archived       bool                   `protobuf:"varint,31,opt,name=archived,proto3" json:"archived,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Order) Reset() {
+	*x = Order{}
+	mi := &file_sensors_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Order) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Order) ProtoMessage() {}
+
+func (x *Order) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Order.ProtoReflect.Descriptor instead.
+func (*Order) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Order) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Order) GetTx() string {
+	if x != nil {
+		return x.Tx
+	}
+	return ""
+}
+
+func (x *Order) GetPendingBlock() int64 {
+	if x != nil {
+		return x.PendingBlock
+	}
+	return 0
+}
+
+func (x *Order) GetCommitBlock() int64 {
+	if x != nil {
+		return x.CommitBlock
+	}
+	return 0
+}
+
+func (x *Order) GetConfirmBlock() int64 {
+	if x != nil {
+		return x.ConfirmBlock
+	}
+	return 0
+}
+
+func (x *Order) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Order) GetCreateTime() int64 {
+	if x != nil {
+		return x.CreateTime
+	}
+	return 0
+}
+
+func (x *Order) GetPendingTime() int64 {
+	if x != nil {
+		return x.PendingTime
+	}
+	return 0
+}
+
+func (x *Order) GetCommitTime() int64 {
+	if x != nil {
+		return x.CommitTime
+	}
+	return 0
+}
+
+func (x *Order) GetConfirmTime() int64 {
+	if x != nil {
+		return x.ConfirmTime
+	}
+	return 0
+}
+
+func (x *Order) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *Order) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *Order) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Order) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Order) GetGasLimits() string {
+	if x != nil {
+		return x.GasLimits
+	}
+	return ""
+}
+
+func (x *Order) GetGasPrice() string {
+	if x != nil {
+		return x.GasPrice
+	}
+	return ""
+}
+
+func (x *Order) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *Order) GetConfirmDepth() int64 {
+	if x != nil {
+		return x.ConfirmDepth
+	}
+	return 0
+}
+
+func (x *Order) GetTimeoutDepth() int64 {
+	if x != nil {
+		return x.TimeoutDepth
+	}
+	return 0
+}
+
+func (x *Order) GetPendingTimeout() int64 {
+	if x != nil {
+		return x.PendingTimeout
+	}
+	return 0
+}
+
+func (x *Order) GetConfirmTimeout() int64 {
+	if x != nil {
+		return x.ConfirmTimeout
+	}
+	return 0
+}
+
+func (x *Order) GetTimeoutReason() string {
+	if x != nil {
+		return x.TimeoutReason
+	}
+	return ""
+}
+
+func (x *Order) GetFailureReason() string {
+	if x != nil {
+		return x.FailureReason
+	}
+	return ""
+}
+
+func (x *Order) GetRevertReason() string {
+	if x != nil {
+		return x.RevertReason
+	}
+	return ""
+}
+
+func (x *Order) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Order) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *Order) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Order) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *Order) GetFiatValue() string {
+	if x != nil {
+		return x.FiatValue
+	}
+	return ""
+}
+
+func (x *Order) GetFiatCurrency() string {
+	if x != nil {
+		return x.FiatCurrency
+	}
+	return ""
+}
+
+func (x *Order) GetArchived() bool {
+	if x != nil {
+		return x.Archived
+	}
+	return false
+}
+
+type NewWatcherRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Watcher       *Watcher               `protobuf:"bytes,1,opt,name=watcher,proto3" json:"watcher,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NewWatcherRequest) Reset() {
+	*x = NewWatcherRequest{}
+	mi := &file_sensors_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NewWatcherRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewWatcherRequest) ProtoMessage() {}
+
+func (x *NewWatcherRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewWatcherRequest.ProtoReflect.Descriptor instead.
+func (*NewWatcherRequest) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NewWatcherRequest) GetWatcher() *Watcher {
+	if x != nil {
+		return x.Watcher
+	}
+	return nil
+}
+
+type NewWatcherResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NewWatcherResponse) Reset() {
+	*x = NewWatcherResponse{}
+	mi := &file_sensors_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NewWatcherResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewWatcherResponse) ProtoMessage() {}
+
+func (x *NewWatcherResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewWatcherResponse.ProtoReflect.Descriptor instead.
+func (*NewWatcherResponse) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *NewWatcherResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteWatcherRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteWatcherRequest) Reset() {
+	*x = DeleteWatcherRequest{}
+	mi := &file_sensors_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteWatcherRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteWatcherRequest) ProtoMessage() {}
+
+func (x *DeleteWatcherRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteWatcherRequest.ProtoReflect.Descriptor instead.
+func (*DeleteWatcherRequest) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeleteWatcherRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type DeleteWatcherResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteWatcherResponse) Reset() {
+	*x = DeleteWatcherResponse{}
+	mi := &file_sensors_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteWatcherResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteWatcherResponse) ProtoMessage() {}
+
+func (x *DeleteWatcherResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteWatcherResponse.ProtoReflect.Descriptor instead.
+func (*DeleteWatcherResponse) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{5}
+}
+
+type ListWatchersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Offset        int64                  `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	OrderBy       string                 `protobuf:"bytes,3,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+	Desc          bool                   `protobuf:"varint,4,opt,name=desc,proto3" json:"desc,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWatchersRequest) Reset() {
+	*x = ListWatchersRequest{}
+	mi := &file_sensors_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWatchersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWatchersRequest) ProtoMessage() {}
+
+func (x *ListWatchersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWatchersRequest.ProtoReflect.Descriptor instead.
+func (*ListWatchersRequest) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListWatchersRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListWatchersRequest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ListWatchersRequest) GetOrderBy() string {
+	if x != nil {
+		return x.OrderBy
+	}
+	return ""
+}
+
+func (x *ListWatchersRequest) GetDesc() bool {
+	if x != nil {
+		return x.Desc
+	}
+	return false
+}
+
+type ListWatchersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Watchers      []*Watcher             `protobuf:"bytes,1,rep,name=watchers,proto3" json:"watchers,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWatchersResponse) Reset() {
+	*x = ListWatchersResponse{}
+	mi := &file_sensors_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWatchersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWatchersResponse) ProtoMessage() {}
+
+func (x *ListWatchersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWatchersResponse.ProtoReflect.Descriptor instead.
+func (*ListWatchersResponse) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListWatchersResponse) GetWatchers() []*Watcher {
+	if x != nil {
+		return x.Watchers
+	}
+	return nil
+}
+
+func (x *ListWatchersResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type WatchOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Cursor        uint64                 `protobuf:"varint,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchOrdersRequest) Reset() {
+	*x = WatchOrdersRequest{}
+	mi := &file_sensors_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchOrdersRequest) ProtoMessage() {}
+
+func (x *WatchOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchOrdersRequest.ProtoReflect.Descriptor instead.
+func (*WatchOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WatchOrdersRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *WatchOrdersRequest) GetCursor() uint64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+type OrderEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cursor        uint64                 `protobuf:"varint,1,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Order         *Order                 `protobuf:"bytes,3,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderEvent) Reset() {
+	*x = OrderEvent{}
+	mi := &file_sensors_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderEvent) ProtoMessage() {}
+
+func (x *OrderEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderEvent.ProtoReflect.Descriptor instead.
+func (*OrderEvent) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *OrderEvent) GetCursor() uint64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+func (x *OrderEvent) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *OrderEvent) GetOrder() *Order {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+type GetOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tx            string                 `protobuf:"bytes,1,opt,name=tx,proto3" json:"tx,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderRequest) Reset() {
+	*x = GetOrderRequest{}
+	mi := &file_sensors_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderRequest) ProtoMessage() {}
+
+func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderRequest) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetOrderRequest) GetTx() string {
+	if x != nil {
+		return x.Tx
+	}
+	return ""
+}
+
+type RecheckOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tx            string                 `protobuf:"bytes,1,opt,name=tx,proto3" json:"tx,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecheckOrderRequest) Reset() {
+	*x = RecheckOrderRequest{}
+	mi := &file_sensors_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecheckOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecheckOrderRequest) ProtoMessage() {}
+
+func (x *RecheckOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecheckOrderRequest.ProtoReflect.Descriptor instead.
+func (*RecheckOrderRequest) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RecheckOrderRequest) GetTx() string {
+	if x != nil {
+		return x.Tx
+	}
+	return ""
+}
+
+type OrderHistory struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Tx            string                 `protobuf:"bytes,2,opt,name=tx,proto3" json:"tx,omitempty"`
+	From          string                 `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	Block         int64                  `protobuf:"varint,5,opt,name=block,proto3" json:"block,omitempty"`
+	Time          int64                  `protobuf:"varint,6,opt,name=time,proto3" json:"time,omitempty"`
+	Reason        string                 `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderHistory) Reset() {
+	*x = OrderHistory{}
+	mi := &file_sensors_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderHistory) ProtoMessage() {}
+
+func (x *OrderHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderHistory.ProtoReflect.Descriptor instead.
+func (*OrderHistory) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *OrderHistory) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *OrderHistory) GetTx() string {
+	if x != nil {
+		return x.Tx
+	}
+	return ""
+}
+
+func (x *OrderHistory) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *OrderHistory) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *OrderHistory) GetBlock() int64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+func (x *OrderHistory) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *OrderHistory) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type GetOrderHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tx            string                 `protobuf:"bytes,1,opt,name=tx,proto3" json:"tx,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderHistoryRequest) Reset() {
+	*x = GetOrderHistoryRequest{}
+	mi := &file_sensors_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderHistoryRequest) ProtoMessage() {}
+
+func (x *GetOrderHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetOrderHistoryRequest) GetTx() string {
+	if x != nil {
+		return x.Tx
+	}
+	return ""
+}
+
+type GetOrderHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Histories     []*OrderHistory        `protobuf:"bytes,1,rep,name=histories,proto3" json:"histories,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderHistoryResponse) Reset() {
+	*x = GetOrderHistoryResponse{}
+	mi := &file_sensors_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderHistoryResponse) ProtoMessage() {}
+
+func (x *GetOrderHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetOrderHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetOrderHistoryResponse) GetHistories() []*OrderHistory {
+	if x != nil {
+		return x.Histories
+	}
+	return nil
+}
+
+type RewindRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Block         int64                  `protobuf:"varint,1,opt,name=block,proto3" json:"block,omitempty"`
+	ChainId       int64                  `protobuf:"varint,2,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RewindRequest) Reset() {
+	*x = RewindRequest{}
+	mi := &file_sensors_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RewindRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RewindRequest) ProtoMessage() {}
+
+func (x *RewindRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RewindRequest.ProtoReflect.Descriptor instead.
+func (*RewindRequest) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RewindRequest) GetBlock() int64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+func (x *RewindRequest) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+type RewindResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RewindResponse) Reset() {
+	*x = RewindResponse{}
+	mi := &file_sensors_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RewindResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RewindResponse) ProtoMessage() {}
+
+func (x *RewindResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RewindResponse.ProtoReflect.Descriptor instead.
+func (*RewindResponse) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{16}
+}
+
+type ReplayOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          int64                  `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            int64                  `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`
+	ChainId       int64                  `protobuf:"varint,3,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayOrdersRequest) Reset() {
+	*x = ReplayOrdersRequest{}
+	mi := &file_sensors_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayOrdersRequest) ProtoMessage() {}
+
+func (x *ReplayOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayOrdersRequest.ProtoReflect.Descriptor instead.
+func (*ReplayOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ReplayOrdersRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *ReplayOrdersRequest) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *ReplayOrdersRequest) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+type ReplayOrdersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Replayed      int64                  `protobuf:"varint,1,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayOrdersResponse) Reset() {
+	*x = ReplayOrdersResponse{}
+	mi := &file_sensors_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayOrdersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayOrdersResponse) ProtoMessage() {}
+
+func (x *ReplayOrdersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayOrdersResponse.ProtoReflect.Descriptor instead.
+func (*ReplayOrdersResponse) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ReplayOrdersResponse) GetReplayed() int64 {
+	if x != nil {
+		return x.Replayed
+	}
+	return 0
+}
+
+type GetStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_sensors_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{19}
+}
+
+type GetStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chains        []*SyncStatus          `protobuf:"bytes,1,rep,name=chains,proto3" json:"chains,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusResponse) Reset() {
+	*x = GetStatusResponse{}
+	mi := &file_sensors_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusResponse) ProtoMessage() {}
+
+func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetStatusResponse) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetStatusResponse) GetChains() []*SyncStatus {
+	if x != nil {
+		return x.Chains
+	}
+	return nil
+}
+
+type SyncStatus struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Block           int64                  `protobuf:"varint,1,opt,name=block,proto3" json:"block,omitempty"`
+	BlockHash       string                 `protobuf:"bytes,2,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	BlockTime       int64                  `protobuf:"varint,3,opt,name=block_time,json=blockTime,proto3" json:"block_time,omitempty"`
+	Head            int64                  `protobuf:"varint,4,opt,name=head,proto3" json:"head,omitempty"`
+	Lag             int64                  `protobuf:"varint,5,opt,name=lag,proto3" json:"lag,omitempty"`
+	LagSeconds      int64                  `protobuf:"varint,6,opt,name=lag_seconds,json=lagSeconds,proto3" json:"lag_seconds,omitempty"`
+	LastProcessed   int64                  `protobuf:"varint,7,opt,name=last_processed,json=lastProcessed,proto3" json:"last_processed,omitempty"`
+	Unconfirmed     int64                  `protobuf:"varint,8,opt,name=unconfirmed,proto3" json:"unconfirmed,omitempty"`
+	NotifierFailing bool                   `protobuf:"varint,9,opt,name=notifier_failing,json=notifierFailing,proto3" json:"notifier_failing,omitempty"`
+	NotifierError   string                 `protobuf:"bytes,10,opt,name=notifier_error,json=notifierError,proto3" json:"notifier_error,omitempty"`
+	ChainId         int64                  `protobuf:"varint,11,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Leader          bool                   `protobuf:"varint,12,opt,name=leader,proto3" json:"leader,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SyncStatus) Reset() {
+	*x = SyncStatus{}
+	mi := &file_sensors_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncStatus) ProtoMessage() {}
+
+func (x *SyncStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_sensors_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncStatus.ProtoReflect.Descriptor instead.
+func (*SyncStatus) Descriptor() ([]byte, []int) {
+	return file_sensors_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SyncStatus) GetBlock() int64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+func (x *SyncStatus) GetBlockHash() string {
+	if x != nil {
+		return x.BlockHash
+	}
+	return ""
+}
+
+func (x *SyncStatus) GetBlockTime() int64 {
+	if x != nil {
+		return x.BlockTime
+	}
+	return 0
+}
+
+func (x *SyncStatus) GetHead() int64 {
+	if x != nil {
+		return x.Head
+	}
+	return 0
+}
+
+func (x *SyncStatus) GetLag() int64 {
+	if x != nil {
+		return x.Lag
+	}
+	return 0
+}
+
+func (x *SyncStatus) GetLagSeconds() int64 {
+	if x != nil {
+		return x.LagSeconds
+	}
+	return 0
+}
+
+func (x *SyncStatus) GetLastProcessed() int64 {
+	if x != nil {
+		return x.LastProcessed
+	}
+	return 0
+}
+
+func (x *SyncStatus) GetUnconfirmed() int64 {
+	if x != nil {
+		return x.Unconfirmed
+	}
+	return 0
+}
+
+func (x *SyncStatus) GetNotifierFailing() bool {
+	if x != nil {
+		return x.NotifierFailing
+	}
+	return false
+}
+
+func (x *SyncStatus) GetNotifierError() string {
+	if x != nil {
+		return x.NotifierError
+	}
+	return ""
+}
+
+func (x *SyncStatus) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *SyncStatus) GetLeader() bool {
+	if x != nil {
+		return x.Leader
+	}
+	return false
+}
+
+var File_sensors_proto protoreflect.FileDescriptor
+
+const file_sensors_proto_rawDesc = "" +
+	"\n" +
+	"\rsensors.proto\x12\asensors\"\x94\x02\n" +
+	"\aWatcher\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x12\x18\n" +
+	"\aaddress\x18\x04 \x01(\tR\aaddress\x12\x14\n" +
+	"\x05erc20\x18\x05 \x01(\bR\x05erc20\x12\x19\n" +
+	"\bchain_id\x18\x06 \x01(\x03R\achainId\x12\x1c\n" +
+	"\tconfirmed\x18\a \x01(\x03R\tconfirmed\x12\x18\n" +
+	"\atimeout\x18\b \x01(\x03R\atimeout\x12'\n" +
+	"\x0fpending_timeout\x18\t \x01(\x03R\x0ependingTimeout\x12'\n" +
+	"\x0fconfirm_timeout\x18\n" +
+	" \x01(\x03R\x0econfirmTimeout\"\xa8\a\n" +
+	"\x05Order\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x0e\n" +
+	"\x02tx\x18\x02 \x01(\tR\x02tx\x12#\n" +
+	"\rpending_block\x18\x03 \x01(\x03R\fpendingBlock\x12!\n" +
+	"\fcommit_block\x18\x04 \x01(\x03R\vcommitBlock\x12#\n" +
+	"\rconfirm_block\x18\x05 \x01(\x03R\fconfirmBlock\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12\x1f\n" +
+	"\vcreate_time\x18\a \x01(\x03R\n" +
+	"createTime\x12!\n" +
+	"\fpending_time\x18\b \x01(\x03R\vpendingTime\x12\x1f\n" +
+	"\vcommit_time\x18\t \x01(\x03R\n" +
+	"commitTime\x12!\n" +
+	"\fconfirm_time\x18\n" +
+	" \x01(\x03R\vconfirmTime\x12\x12\n" +
+	"\x04from\x18\v \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\f \x01(\tR\x02to\x12\x14\n" +
+	"\x05value\x18\r \x01(\tR\x05value\x12\x12\n" +
+	"\x04code\x18\x0e \x01(\tR\x04code\x12\x1d\n" +
+	"\n" +
+	"gas_limits\x18\x0f \x01(\tR\tgasLimits\x12\x1b\n" +
+	"\tgas_price\x18\x10 \x01(\tR\bgasPrice\x12\x19\n" +
+	"\bchain_id\x18\x11 \x01(\x03R\achainId\x12#\n" +
+	"\rconfirm_depth\x18\x12 \x01(\x03R\fconfirmDepth\x12#\n" +
+	"\rtimeout_depth\x18\x13 \x01(\x03R\ftimeoutDepth\x12'\n" +
+	"\x0fpending_timeout\x18\x14 \x01(\x03R\x0ependingTimeout\x12'\n" +
+	"\x0fconfirm_timeout\x18\x15 \x01(\x03R\x0econfirmTimeout\x12%\n" +
+	"\x0etimeout_reason\x18\x16 \x01(\tR\rtimeoutReason\x12%\n" +
+	"\x0efailure_reason\x18\x17 \x01(\tR\rfailureReason\x12#\n" +
+	"\rrevert_reason\x18\x18 \x01(\tR\frevertReason\x12\x18\n" +
+	"\aversion\x18\x19 \x01(\x03R\aversion\x12\x14\n" +
+	"\x05token\x18\x1a \x01(\tR\x05token\x12\x16\n" +
+	"\x06symbol\x18\x1b \x01(\tR\x06symbol\x12\x16\n" +
+	"\x06amount\x18\x1c \x01(\tR\x06amount\x12\x1d\n" +
+	"\n" +
+	"fiat_value\x18\x1d \x01(\tR\tfiatValue\x12#\n" +
+	"\rfiat_currency\x18\x1e \x01(\tR\ffiatCurrency\x12\x1a\n" +
+	"\barchived\x18\x1f \x01(\bR\barchived\"?\n" +
+	"\x11NewWatcherRequest\x12*\n" +
+	"\awatcher\x18\x01 \x01(\v2\x10.sensors.WatcherR\awatcher\"$\n" +
+	"\x12NewWatcherResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"(\n" +
+	"\x14DeleteWatcherRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"\x17\n" +
+	"\x15DeleteWatcherResponse\"p\n" +
+	"\x13ListWatchersRequest\x12\x16\n" +
+	"\x06offset\x18\x01 \x01(\x03R\x06offset\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x19\n" +
+	"\border_by\x18\x03 \x01(\tR\aorderBy\x12\x12\n" +
+	"\x04desc\x18\x04 \x01(\bR\x04desc\"Z\n" +
+	"\x14ListWatchersResponse\x12,\n" +
+	"\bwatchers\x18\x01 \x03(\v2\x10.sensors.WatcherR\bwatchers\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\">\n" +
+	"\x12WatchOrdersRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\x04R\x06cursor\"\\\n" +
+	"\n" +
+	"OrderEvent\x12\x16\n" +
+	"\x06cursor\x18\x01 \x01(\x04R\x06cursor\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12$\n" +
+	"\x05order\x18\x03 \x01(\v2\x0e.sensors.OrderR\x05order\"!\n" +
+	"\x0fGetOrderRequest\x12\x0e\n" +
+	"\x02tx\x18\x01 \x01(\tR\x02tx\"%\n" +
+	"\x13RecheckOrderRequest\x12\x0e\n" +
+	"\x02tx\x18\x01 \x01(\tR\x02tx\"\x9f\x01\n" +
+	"\fOrderHistory\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x0e\n" +
+	"\x02tx\x18\x02 \x01(\tR\x02tx\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x04 \x01(\tR\x02to\x12\x14\n" +
+	"\x05block\x18\x05 \x01(\x03R\x05block\x12\x12\n" +
+	"\x04time\x18\x06 \x01(\x03R\x04time\x12\x16\n" +
+	"\x06reason\x18\a \x01(\tR\x06reason\"(\n" +
+	"\x16GetOrderHistoryRequest\x12\x0e\n" +
+	"\x02tx\x18\x01 \x01(\tR\x02tx\"N\n" +
+	"\x17GetOrderHistoryResponse\x123\n" +
+	"\thistories\x18\x01 \x03(\v2\x15.sensors.OrderHistoryR\thistories\"@\n" +
+	"\rRewindRequest\x12\x14\n" +
+	"\x05block\x18\x01 \x01(\x03R\x05block\x12\x19\n" +
+	"\bchain_id\x18\x02 \x01(\x03R\achainId\"\x10\n" +
+	"\x0eRewindResponse\"T\n" +
+	"\x13ReplayOrdersRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\x03R\x02to\x12\x19\n" +
+	"\bchain_id\x18\x03 \x01(\x03R\achainId\"2\n" +
+	"\x14ReplayOrdersResponse\x12\x1a\n" +
+	"\breplayed\x18\x01 \x01(\x03R\breplayed\"\x12\n" +
+	"\x10GetStatusRequest\"@\n" +
+	"\x11GetStatusResponse\x12+\n" +
+	"\x06chains\x18\x01 \x03(\v2\x13.sensors.SyncStatusR\x06chains\"\xf5\x02\n" +
+	"\n" +
+	"SyncStatus\x12\x14\n" +
+	"\x05block\x18\x01 \x01(\x03R\x05block\x12\x1d\n" +
+	"\n" +
+	"block_hash\x18\x02 \x01(\tR\tblockHash\x12\x1d\n" +
+	"\n" +
+	"block_time\x18\x03 \x01(\x03R\tblockTime\x12\x12\n" +
+	"\x04head\x18\x04 \x01(\x03R\x04head\x12\x10\n" +
+	"\x03lag\x18\x05 \x01(\x03R\x03lag\x12\x1f\n" +
+	"\vlag_seconds\x18\x06 \x01(\x03R\n" +
+	"lagSeconds\x12%\n" +
+	"\x0elast_processed\x18\a \x01(\x03R\rlastProcessed\x12 \n" +
+	"\vunconfirmed\x18\b \x01(\x03R\vunconfirmed\x12)\n" +
+	"\x10notifier_failing\x18\t \x01(\bR\x0fnotifierFailing\x12%\n" +
+	"\x0enotifier_error\x18\n" +
+	" \x01(\tR\rnotifierError\x12\x19\n" +
+	"\bchain_id\x18\v \x01(\x03R\achainId\x12\x16\n" +
+	"\x06leader\x18\f \x01(\bR\x06leader2\xc6\x05\n" +
+	"\aSensors\x12E\n" +
+	"\n" +
+	"NewWatcher\x12\x1a.sensors.NewWatcherRequest\x1a\x1b.sensors.NewWatcherResponse\x12N\n" +
+	"\rDeleteWatcher\x12\x1d.sensors.DeleteWatcherRequest\x1a\x1e.sensors.DeleteWatcherResponse\x12K\n" +
+	"\fListWatchers\x12\x1c.sensors.ListWatchersRequest\x1a\x1d.sensors.ListWatchersResponse\x12A\n" +
+	"\vWatchOrders\x12\x1b.sensors.WatchOrdersRequest\x1a\x13.sensors.OrderEvent0\x01\x124\n" +
+	"\bGetOrder\x12\x18.sensors.GetOrderRequest\x1a\x0e.sensors.Order\x12<\n" +
+	"\fRecheckOrder\x12\x1c.sensors.RecheckOrderRequest\x1a\x0e.sensors.Order\x12T\n" +
+	"\x0fGetOrderHistory\x12\x1f.sensors.GetOrderHistoryRequest\x1a .sensors.GetOrderHistoryResponse\x129\n" +
+	"\x06Rewind\x12\x16.sensors.RewindRequest\x1a\x17.sensors.RewindResponse\x12K\n" +
+	"\fReplayOrders\x12\x1c.sensors.ReplayOrdersRequest\x1a\x1d.sensors.ReplayOrdersResponse\x12B\n" +
+	"\tGetStatus\x12\x19.sensors.GetStatusRequest\x1a\x1a.sensors.GetStatusResponseB+Z)github.com/laplacenetwork/eth-sensors/apib\x06proto3"
+
+var (
+	file_sensors_proto_rawDescOnce sync.Once
+	file_sensors_proto_rawDescData []byte
+)
+
+func file_sensors_proto_rawDescGZIP() []byte {
+	file_sensors_proto_rawDescOnce.Do(func() {
+		file_sensors_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sensors_proto_rawDesc), len(file_sensors_proto_rawDesc)))
+	})
+	return file_sensors_proto_rawDescData
+}
+
+var file_sensors_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_sensors_proto_goTypes = []any{
+	(*Watcher)(nil),                 // 0: sensors.Watcher
+	(*Order)(nil),                   // 1: sensors.Order
+	(*NewWatcherRequest)(nil),       // 2: sensors.NewWatcherRequest
+	(*NewWatcherResponse)(nil),      // 3: sensors.NewWatcherResponse
+	(*DeleteWatcherRequest)(nil),    // 4: sensors.DeleteWatcherRequest
+	(*DeleteWatcherResponse)(nil),   // 5: sensors.DeleteWatcherResponse
+	(*ListWatchersRequest)(nil),     // 6: sensors.ListWatchersRequest
+	(*ListWatchersResponse)(nil),    // 7: sensors.ListWatchersResponse
+	(*WatchOrdersRequest)(nil),      // 8: sensors.WatchOrdersRequest
+	(*OrderEvent)(nil),              // 9: sensors.OrderEvent
+	(*GetOrderRequest)(nil),         // 10: sensors.GetOrderRequest
+	(*RecheckOrderRequest)(nil),     // 11: sensors.RecheckOrderRequest
+	(*OrderHistory)(nil),            // 12: sensors.OrderHistory
+	(*GetOrderHistoryRequest)(nil),  // 13: sensors.GetOrderHistoryRequest
+	(*GetOrderHistoryResponse)(nil), // 14: sensors.GetOrderHistoryResponse
+	(*RewindRequest)(nil),           // 15: sensors.RewindRequest
+	(*RewindResponse)(nil),          // 16: sensors.RewindResponse
+	(*ReplayOrdersRequest)(nil),     // 17: sensors.ReplayOrdersRequest
+	(*ReplayOrdersResponse)(nil),    // 18: sensors.ReplayOrdersResponse
+	(*GetStatusRequest)(nil),        // 19: sensors.GetStatusRequest
+	(*GetStatusResponse)(nil),       // 20: sensors.GetStatusResponse
+	(*SyncStatus)(nil),              // 21: sensors.SyncStatus
+}
+var file_sensors_proto_depIdxs = []int32{
+	0,  // 0: sensors.NewWatcherRequest.watcher:type_name -> sensors.Watcher
+	0,  // 1: sensors.ListWatchersResponse.watchers:type_name -> sensors.Watcher
+	1,  // 2: sensors.OrderEvent.order:type_name -> sensors.Order
+	12, // 3: sensors.GetOrderHistoryResponse.histories:type_name -> sensors.OrderHistory
+	21, // 4: sensors.GetStatusResponse.chains:type_name -> sensors.SyncStatus
+	2,  // 5: sensors.Sensors.NewWatcher:input_type -> sensors.NewWatcherRequest
+	4,  // 6: sensors.Sensors.DeleteWatcher:input_type -> sensors.DeleteWatcherRequest
+	6,  // 7: sensors.Sensors.ListWatchers:input_type -> sensors.ListWatchersRequest
+	8,  // 8: sensors.Sensors.WatchOrders:input_type -> sensors.WatchOrdersRequest
+	10, // 9: sensors.Sensors.GetOrder:input_type -> sensors.GetOrderRequest
+	11, // 10: sensors.Sensors.RecheckOrder:input_type -> sensors.RecheckOrderRequest
+	13, // 11: sensors.Sensors.GetOrderHistory:input_type -> sensors.GetOrderHistoryRequest
+	15, // 12: sensors.Sensors.Rewind:input_type -> sensors.RewindRequest
+	17, // 13: sensors.Sensors.ReplayOrders:input_type -> sensors.ReplayOrdersRequest
+	19, // 14: sensors.Sensors.GetStatus:input_type -> sensors.GetStatusRequest
+	3,  // 15: sensors.Sensors.NewWatcher:output_type -> sensors.NewWatcherResponse
+	5,  // 16: sensors.Sensors.DeleteWatcher:output_type -> sensors.DeleteWatcherResponse
+	7,  // 17: sensors.Sensors.ListWatchers:output_type -> sensors.ListWatchersResponse
+	9,  // 18: sensors.Sensors.WatchOrders:output_type -> sensors.OrderEvent
+	1,  // 19: sensors.Sensors.GetOrder:output_type -> sensors.Order
+	1,  // 20: sensors.Sensors.RecheckOrder:output_type -> sensors.Order
+	14, // 21: sensors.Sensors.GetOrderHistory:output_type -> sensors.GetOrderHistoryResponse
+	16, // 22: sensors.Sensors.Rewind:output_type -> sensors.RewindResponse
+	18, // 23: sensors.Sensors.ReplayOrders:output_type -> sensors.ReplayOrdersResponse
+	20, // 24: sensors.Sensors.GetStatus:output_type -> sensors.GetStatusResponse
+	15, // [15:25] is the sub-list for method output_type
+	5,  // [5:15] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_sensors_proto_init() }
+func file_sensors_proto_init() {
+	if File_sensors_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sensors_proto_rawDesc), len(file_sensors_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   22,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_sensors_proto_goTypes,
+		DependencyIndexes: file_sensors_proto_depIdxs,
+		MessageInfos:      file_sensors_proto_msgTypes,
+	}.Build()
+	File_sensors_proto = out.File
+	file_sensors_proto_goTypes = nil
+	file_sensors_proto_depIdxs = nil
+}
@@ -0,0 +1,457 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: sensors.proto
+
+package api
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// SensorsClient is the client API for Sensors service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SensorsClient interface {
+	NewWatcher(ctx context.Context, in *NewWatcherRequest, opts ...grpc.CallOption) (*NewWatcherResponse, error)
+	DeleteWatcher(ctx context.Context, in *DeleteWatcherRequest, opts ...grpc.CallOption) (*DeleteWatcherResponse, error)
+	ListWatchers(ctx context.Context, in *ListWatchersRequest, opts ...grpc.CallOption) (*ListWatchersResponse, error)
+	WatchOrders(ctx context.Context, in *WatchOrdersRequest, opts ...grpc.CallOption) (Sensors_WatchOrdersClient, error)
+	GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	RecheckOrder(ctx context.Context, in *RecheckOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	GetOrderHistory(ctx context.Context, in *GetOrderHistoryRequest, opts ...grpc.CallOption) (*GetOrderHistoryResponse, error)
+	Rewind(ctx context.Context, in *RewindRequest, opts ...grpc.CallOption) (*RewindResponse, error)
+	ReplayOrders(ctx context.Context, in *ReplayOrdersRequest, opts ...grpc.CallOption) (*ReplayOrdersResponse, error)
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error)
+}
+
+type sensorsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSensorsClient(cc grpc.ClientConnInterface) SensorsClient {
+	return &sensorsClient{cc}
+}
+
+func (c *sensorsClient) NewWatcher(ctx context.Context, in *NewWatcherRequest, opts ...grpc.CallOption) (*NewWatcherResponse, error) {
+	out := new(NewWatcherResponse)
+	err := c.cc.Invoke(ctx, "/sensors.Sensors/NewWatcher", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorsClient) DeleteWatcher(ctx context.Context, in *DeleteWatcherRequest, opts ...grpc.CallOption) (*DeleteWatcherResponse, error) {
+	out := new(DeleteWatcherResponse)
+	err := c.cc.Invoke(ctx, "/sensors.Sensors/DeleteWatcher", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorsClient) ListWatchers(ctx context.Context, in *ListWatchersRequest, opts ...grpc.CallOption) (*ListWatchersResponse, error) {
+	out := new(ListWatchersResponse)
+	err := c.cc.Invoke(ctx, "/sensors.Sensors/ListWatchers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorsClient) WatchOrders(ctx context.Context, in *WatchOrdersRequest, opts ...grpc.CallOption) (Sensors_WatchOrdersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Sensors_ServiceDesc.Streams[0], "/sensors.Sensors/WatchOrders", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sensorsWatchOrdersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Sensors_WatchOrdersClient interface {
+	Recv() (*OrderEvent, error)
+	grpc.ClientStream
+}
+
+type sensorsWatchOrdersClient struct {
+	grpc.ClientStream
+}
+
+func (x *sensorsWatchOrdersClient) Recv() (*OrderEvent, error) {
+	m := new(OrderEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sensorsClient) GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, "/sensors.Sensors/GetOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorsClient) RecheckOrder(ctx context.Context, in *RecheckOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, "/sensors.Sensors/RecheckOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorsClient) GetOrderHistory(ctx context.Context, in *GetOrderHistoryRequest, opts ...grpc.CallOption) (*GetOrderHistoryResponse, error) {
+	out := new(GetOrderHistoryResponse)
+	err := c.cc.Invoke(ctx, "/sensors.Sensors/GetOrderHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorsClient) Rewind(ctx context.Context, in *RewindRequest, opts ...grpc.CallOption) (*RewindResponse, error) {
+	out := new(RewindResponse)
+	err := c.cc.Invoke(ctx, "/sensors.Sensors/Rewind", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorsClient) ReplayOrders(ctx context.Context, in *ReplayOrdersRequest, opts ...grpc.CallOption) (*ReplayOrdersResponse, error) {
+	out := new(ReplayOrdersResponse)
+	err := c.cc.Invoke(ctx, "/sensors.Sensors/ReplayOrders", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorsClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error) {
+	out := new(GetStatusResponse)
+	err := c.cc.Invoke(ctx, "/sensors.Sensors/GetStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SensorsServer is the server API for Sensors service.
+// All implementations must embed UnimplementedSensorsServer
+// for forward compatibility
+type SensorsServer interface {
+	NewWatcher(context.Context, *NewWatcherRequest) (*NewWatcherResponse, error)
+	DeleteWatcher(context.Context, *DeleteWatcherRequest) (*DeleteWatcherResponse, error)
+	ListWatchers(context.Context, *ListWatchersRequest) (*ListWatchersResponse, error)
+	WatchOrders(*WatchOrdersRequest, Sensors_WatchOrdersServer) error
+	GetOrder(context.Context, *GetOrderRequest) (*Order, error)
+	RecheckOrder(context.Context, *RecheckOrderRequest) (*Order, error)
+	GetOrderHistory(context.Context, *GetOrderHistoryRequest) (*GetOrderHistoryResponse, error)
+	Rewind(context.Context, *RewindRequest) (*RewindResponse, error)
+	ReplayOrders(context.Context, *ReplayOrdersRequest) (*ReplayOrdersResponse, error)
+	GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error)
+	mustEmbedUnimplementedSensorsServer()
+}
+
+// UnimplementedSensorsServer must be embedded to have forward compatible implementations.
+type UnimplementedSensorsServer struct {
+}
+
+func (UnimplementedSensorsServer) NewWatcher(context.Context, *NewWatcherRequest) (*NewWatcherResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NewWatcher not implemented")
+}
+func (UnimplementedSensorsServer) DeleteWatcher(context.Context, *DeleteWatcherRequest) (*DeleteWatcherResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWatcher not implemented")
+}
+func (UnimplementedSensorsServer) ListWatchers(context.Context, *ListWatchersRequest) (*ListWatchersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWatchers not implemented")
+}
+func (UnimplementedSensorsServer) WatchOrders(*WatchOrdersRequest, Sensors_WatchOrdersServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchOrders not implemented")
+}
+func (UnimplementedSensorsServer) GetOrder(context.Context, *GetOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrder not implemented")
+}
+func (UnimplementedSensorsServer) RecheckOrder(context.Context, *RecheckOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecheckOrder not implemented")
+}
+func (UnimplementedSensorsServer) GetOrderHistory(context.Context, *GetOrderHistoryRequest) (*GetOrderHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrderHistory not implemented")
+}
+func (UnimplementedSensorsServer) Rewind(context.Context, *RewindRequest) (*RewindResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rewind not implemented")
+}
+func (UnimplementedSensorsServer) ReplayOrders(context.Context, *ReplayOrdersRequest) (*ReplayOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayOrders not implemented")
+}
+func (UnimplementedSensorsServer) GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedSensorsServer) mustEmbedUnimplementedSensorsServer() {}
+
+// UnsafeSensorsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SensorsServer will
+// result in compilation errors.
+type UnsafeSensorsServer interface {
+	mustEmbedUnimplementedSensorsServer()
+}
+
+func RegisterSensorsServer(s grpc.ServiceRegistrar, srv SensorsServer) {
+	s.RegisterService(&Sensors_ServiceDesc, srv)
+}
+
+func _Sensors_NewWatcher_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewWatcherRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorsServer).NewWatcher(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sensors.Sensors/NewWatcher",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorsServer).NewWatcher(ctx, req.(*NewWatcherRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sensors_DeleteWatcher_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWatcherRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorsServer).DeleteWatcher(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sensors.Sensors/DeleteWatcher",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorsServer).DeleteWatcher(ctx, req.(*DeleteWatcherRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sensors_ListWatchers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWatchersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorsServer).ListWatchers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sensors.Sensors/ListWatchers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorsServer).ListWatchers(ctx, req.(*ListWatchersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sensors_WatchOrders_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchOrdersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SensorsServer).WatchOrders(m, &sensorsWatchOrdersServer{stream})
+}
+
+type Sensors_WatchOrdersServer interface {
+	Send(*OrderEvent) error
+	grpc.ServerStream
+}
+
+type sensorsWatchOrdersServer struct {
+	grpc.ServerStream
+}
+
+func (x *sensorsWatchOrdersServer) Send(m *OrderEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Sensors_GetOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorsServer).GetOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sensors.Sensors/GetOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorsServer).GetOrder(ctx, req.(*GetOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sensors_RecheckOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecheckOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorsServer).RecheckOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sensors.Sensors/RecheckOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorsServer).RecheckOrder(ctx, req.(*RecheckOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sensors_GetOrderHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorsServer).GetOrderHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sensors.Sensors/GetOrderHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorsServer).GetOrderHistory(ctx, req.(*GetOrderHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sensors_Rewind_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RewindRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorsServer).Rewind(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sensors.Sensors/Rewind",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorsServer).Rewind(ctx, req.(*RewindRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sensors_ReplayOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorsServer).ReplayOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sensors.Sensors/ReplayOrders",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorsServer).ReplayOrders(ctx, req.(*ReplayOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sensors_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorsServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sensors.Sensors/GetStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorsServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Sensors_ServiceDesc is the grpc.ServiceDesc for Sensors service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Sensors_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sensors.Sensors",
+	HandlerType: (*SensorsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "NewWatcher",
+			Handler:    _Sensors_NewWatcher_Handler,
+		},
+		{
+			MethodName: "DeleteWatcher",
+			Handler:    _Sensors_DeleteWatcher_Handler,
+		},
+		{
+			MethodName: "ListWatchers",
+			Handler:    _Sensors_ListWatchers_Handler,
+		},
+		{
+			MethodName: "GetOrder",
+			Handler:    _Sensors_GetOrder_Handler,
+		},
+		{
+			MethodName: "RecheckOrder",
+			Handler:    _Sensors_RecheckOrder_Handler,
+		},
+		{
+			MethodName: "GetOrderHistory",
+			Handler:    _Sensors_GetOrderHistory_Handler,
+		},
+		{
+			MethodName: "Rewind",
+			Handler:    _Sensors_Rewind_Handler,
+		},
+		{
+			MethodName: "ReplayOrders",
+			Handler:    _Sensors_ReplayOrders_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _Sensors_GetStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchOrders",
+			Handler:       _Sensors_WatchOrders_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sensors.proto",
+}
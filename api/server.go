@@ -0,0 +1,360 @@
+package api
+
+//go:generate protoc --go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. sensors.proto
+
+import (
+	"context"
+
+	"github.com/dynamicgo/orm"
+	"github.com/dynamicgo/slf4go"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const replayBatch = 100
+
+// Server the sensors grpc service implementation
+type Server struct {
+	UnimplementedSensorsServer
+	slf4go.Logger
+	sensor sensors.Sensor
+	broker *Broker
+}
+
+// NewServer create grpc service with sensor and the broker used as the sensor's notifier
+func NewServer(sensor sensors.Sensor, broker *Broker) *Server {
+	return &Server{
+		Logger: slf4go.Get("api-server"),
+		sensor: sensor,
+		broker: broker,
+	}
+}
+
+// Register register service to grpc server
+func (server *Server) Register(s *grpc.Server) {
+	RegisterSensorsServer(s, server)
+}
+
+// NewWatcher implement SensorsServer
+func (server *Server) NewWatcher(ctx context.Context, req *NewWatcherRequest) (*NewWatcherResponse, error) {
+	if req.Watcher == nil {
+		return nil, status.Error(codes.InvalidArgument, "expect watcher")
+	}
+
+	id, err := server.sensor.New(fromWatcherPB(req.Watcher))
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &NewWatcherResponse{Id: id}, nil
+}
+
+// DeleteWatcher implement SensorsServer
+func (server *Server) DeleteWatcher(ctx context.Context, req *DeleteWatcherRequest) (*DeleteWatcherResponse, error) {
+	if err := server.sensor.Delete(req.Key); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &DeleteWatcherResponse{}, nil
+}
+
+// ListWatchers implement SensorsServer
+func (server *Server) ListWatchers(ctx context.Context, req *ListWatchersRequest) (*ListWatchersResponse, error) {
+	page := orm.Page{
+		Offset:  uint64(req.Offset),
+		Size:    uint64(req.Size),
+		OrderBy: req.OrderBy,
+	}
+
+	if req.Desc {
+		page.Order = orm.DESC
+	}
+
+	watchers, total, err := server.sensor.List(page)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &ListWatchersResponse{
+		Total: total,
+	}
+
+	for _, watcher := range watchers {
+		resp.Watchers = append(resp.Watchers, toWatcherPB(watcher))
+	}
+
+	return resp, nil
+}
+
+// WatchOrders implement SensorsServer
+func (server *Server) WatchOrders(req *WatchOrdersRequest, stream Sensors_WatchOrdersServer) error {
+
+	if server.broker == nil {
+		return status.Error(codes.Unavailable, "sensor notifier is not the api broker")
+	}
+
+	if req.Key == "" {
+		return status.Error(codes.InvalidArgument, "expect watcher key")
+	}
+
+	// subscribe before replay, so no event will be lost between replay and live stream
+	sub := server.broker.subscribe(req.Key)
+	defer server.broker.unsubscribe(sub)
+
+	cursor := int64(req.Cursor)
+
+	if cursor > 0 {
+		for {
+			events, err := server.broker.Since(req.Key, cursor, replayBatch)
+
+			if err != nil {
+				server.ErrorF("replay watcher %s events from %d err: %s", req.Key, cursor, err)
+				return status.Error(codes.Internal, err.Error())
+			}
+
+			for _, event := range events {
+				if err := server.send(stream, event); err != nil {
+					return err
+				}
+
+				cursor = event.ID
+			}
+
+			if len(events) < replayBatch {
+				break
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case event, ok := <-sub.C:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "subscriber too slow, resume from the last cursor")
+			}
+
+			if event.ID <= cursor {
+				continue
+			}
+
+			if err := server.send(stream, event); err != nil {
+				return err
+			}
+
+			cursor = event.ID
+		}
+	}
+}
+
+// GetOrder implement SensorsServer
+func (server *Server) GetOrder(ctx context.Context, req *GetOrderRequest) (*Order, error) {
+	order, err := server.sensor.Order(req.Tx)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toOrderPB(order), nil
+}
+
+// RecheckOrder implement SensorsServer
+func (server *Server) RecheckOrder(ctx context.Context, req *RecheckOrderRequest) (*Order, error) {
+	order, err := server.sensor.Recheck(req.Tx)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toOrderPB(order), nil
+}
+
+// GetOrderHistory implement SensorsServer
+func (server *Server) GetOrderHistory(ctx context.Context, req *GetOrderHistoryRequest) (*GetOrderHistoryResponse, error) {
+	histories, err := server.sensor.History(req.Tx)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &GetOrderHistoryResponse{}
+
+	for _, history := range histories {
+		resp.Histories = append(resp.Histories, &OrderHistory{
+			OrderId: history.OrderID,
+			Tx:      history.TX,
+			From:    string(history.From),
+			To:      string(history.To),
+			Block:   history.Block,
+			Time:    history.Time.Unix(),
+			Reason:  history.Reason,
+		})
+	}
+
+	return resp, nil
+}
+
+// Rewind implement SensorsServer
+func (server *Server) Rewind(ctx context.Context, req *RewindRequest) (*RewindResponse, error) {
+	if err := server.sensor.Rewind(req.ChainId, req.Block); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &RewindResponse{}, nil
+}
+
+// ReplayOrders implement SensorsServer
+func (server *Server) ReplayOrders(ctx context.Context, req *ReplayOrdersRequest) (*ReplayOrdersResponse, error) {
+	if req.From > req.To {
+		return nil, status.Error(codes.InvalidArgument, "expect from <= to")
+	}
+
+	replayed, err := server.sensor.Replay(req.ChainId, req.From, req.To)
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &ReplayOrdersResponse{Replayed: int64(replayed)}, nil
+}
+
+// GetStatus implement SensorsServer
+func (server *Server) GetStatus(ctx context.Context, req *GetStatusRequest) (*GetStatusResponse, error) {
+	statuses, err := server.sensor.Status()
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &GetStatusResponse{}
+
+	for _, status := range statuses {
+		resp.Chains = append(resp.Chains, &SyncStatus{
+			ChainId:         status.ChainID,
+			Block:           status.Block,
+			BlockHash:       status.BlockHash,
+			BlockTime:       status.BlockTime.Unix(),
+			Head:            status.Head,
+			Lag:             status.Lag,
+			LagSeconds:      status.LagSeconds,
+			LastProcessed:   status.LastProcessed.Unix(),
+			Unconfirmed:     int64(status.Unconfirmed),
+			NotifierFailing: status.NotifierFailing,
+			NotifierError:   status.NotifierError,
+			Leader:          status.Leader,
+		})
+	}
+
+	return resp, nil
+}
+
+func (server *Server) send(stream Sensors_WatchOrdersServer, event *Event) error {
+	order, err := event.Order()
+
+	if err != nil {
+		server.ErrorF("decode event %d err: %s", event.ID, err)
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return stream.Send(&OrderEvent{
+		Cursor: uint64(event.ID),
+		Key:    event.Key,
+		Order:  toOrderPB(order),
+	})
+}
+
+func toStatus(err error) error {
+	if _, ok := err.(*sensors.TransitionError); ok {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	switch err {
+	case sensors.ErrWatcherExists:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case sensors.ErrOrderNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case sensors.ErrOrderRunning, sensors.ErrOrderArchived:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case sensors.ErrNotSupport:
+		return status.Error(codes.Unimplemented, err.Error())
+	case sensors.ErrChainNotFound:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case sensors.ErrVersion:
+		return status.Error(codes.Aborted, err.Error())
+	case sensors.ErrNotLeader:
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func fromWatcherPB(watcher *Watcher) *sensors.Watcher {
+	return &sensors.Watcher{
+		ID:             watcher.Id,
+		Name:           watcher.Name,
+		Key:            watcher.Key,
+		ChainID:        watcher.ChainId,
+		Address:        watcher.Address,
+		ERC20:          watcher.Erc20,
+		Confirmed:      watcher.Confirmed,
+		Timeout:        watcher.Timeout,
+		PendingTimeout: watcher.PendingTimeout,
+		ConfirmTimeout: watcher.ConfirmTimeout,
+	}
+}
+
+func toWatcherPB(watcher *sensors.Watcher) *Watcher {
+	return &Watcher{
+		Id:             watcher.ID,
+		Name:           watcher.Name,
+		Key:            watcher.Key,
+		ChainId:        watcher.ChainID,
+		Address:        watcher.Address,
+		Erc20:          watcher.ERC20,
+		Confirmed:      watcher.Confirmed,
+		Timeout:        watcher.Timeout,
+		PendingTimeout: watcher.PendingTimeout,
+		ConfirmTimeout: watcher.ConfirmTimeout,
+	}
+}
+
+func toOrderPB(order *sensors.Order) *Order {
+	return &Order{
+		Id:             order.ID,
+		ChainId:        order.ChainID,
+		Tx:             order.TX,
+		PendingBlock:   order.PendingBlock,
+		CommitBlock:    order.CommitBlock,
+		ConfirmBlock:   order.ConfirmBlock,
+		Status:         string(order.Status),
+		CreateTime:     order.CreateTime.Unix(),
+		PendingTime:    order.PendingTime.Unix(),
+		CommitTime:     order.CommitTime.Unix(),
+		ConfirmTime:    order.ConfirmTime.Unix(),
+		From:           order.From,
+		To:             order.To,
+		Value:          order.Value,
+		Code:           order.Code,
+		GasLimits:      order.GasLimits,
+		GasPrice:       order.GasPrice,
+		ConfirmDepth:   order.ConfirmDepth,
+		TimeoutDepth:   order.TimeoutDepth,
+		PendingTimeout: order.PendingTimeout,
+		ConfirmTimeout: order.ConfirmTimeout,
+		TimeoutReason:  string(order.TimeoutReason),
+		FailureReason:  string(order.FailureReason),
+		RevertReason:   order.RevertReason,
+		Version:        order.Version,
+		Token:          order.Token,
+		Symbol:         order.Symbol,
+		Amount:         order.Amount,
+		FiatValue:      order.FiatValue,
+		FiatCurrency:   order.FiatCurrency,
+		Archived:       order.Archived,
+	}
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xorm/xorm"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/laplacenetwork/eth-sensors/sensorstest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// orderStream the fake WatchOrders stream calling onSend after every sent event
+type orderStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events []*OrderEvent
+	onSend func(event *OrderEvent)
+}
+
+func (stream *orderStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (stream *orderStream) Send(event *OrderEvent) error {
+	stream.events = append(stream.events, event)
+
+	stream.onSend(event)
+
+	return nil
+}
+
+func newTestBroker(t *testing.T) (*Broker, func()) {
+	dir, err := ioutil.TempDir("", "eth-sensors-api")
+
+	require.NoError(t, err)
+
+	engine, err := xorm.NewEngine("sqlite3", filepath.Join(dir, "sensors.db"))
+
+	require.NoError(t, err)
+	require.NoError(t, engine.Sync2(new(Event)))
+
+	clock := sensorstest.NewClock(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	return NewBrokerWithEngine(engine, clock, 10), func() {
+		engine.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestWatchOrdersResume(t *testing.T) {
+	broker, closer := newTestBroker(t)
+	defer closer()
+
+	watcher := &sensors.Watcher{Key: "receiver"}
+	other := &sensors.Watcher{Key: "other"}
+
+	notify := func(watcher *sensors.Watcher) {
+		require.NoError(t, broker.Notify(watcher, &sensors.Order{ID: "O_1", TX: "0x1", Status: sensors.StatusRunning}))
+	}
+
+	// the replay takes two batches from cursor 1
+	for i := 0; i < replayBatch+1; i++ {
+		notify(watcher)
+	}
+
+	notify(other)
+
+	server := NewServer(nil, broker)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := &orderStream{ctx: ctx}
+
+	stream.onSend = func(event *OrderEvent) {
+		switch len(stream.events) {
+		case 1:
+			// published after subscribe, both replayed by the second batch and received by the subscriber
+			notify(watcher)
+		case replayBatch + 1:
+			// published after the replay, only received by the subscriber
+			notify(watcher)
+		case replayBatch + 2:
+			cancel()
+		}
+	}
+
+	err := server.WatchOrders(&WatchOrdersRequest{Key: watcher.Key, Cursor: 1}, stream)
+
+	require.Equal(t, context.Canceled, err)
+	require.Len(t, stream.events, replayBatch+2)
+
+	for i, event := range stream.events {
+		require.Equal(t, watcher.Key, event.Key)
+		require.Equal(t, "O_1", event.Order.Id)
+
+		if i > 0 {
+			require.True(t, event.Cursor > stream.events[i-1].Cursor, "duplicated event %d", event.Cursor)
+		}
+	}
+
+	require.Equal(t, uint64(2), stream.events[0].Cursor)
+
+	// stamped by the sensor clock
+	events, err := broker.Since(watcher.Key, 0, 1)
+
+	require.NoError(t, err)
+	require.True(t, broker.clock.Now().Equal(events[0].CreateTime))
+}
+
+func TestWatchOrdersConcurrentNotify(t *testing.T) {
+	broker, closer := newTestBroker(t)
+	defer closer()
+
+	broker.buffer = 100
+
+	watcher := &sensors.Watcher{Key: "receiver"}
+
+	server := NewServer(nil, broker)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const chains, orders = 4, 10
+
+	stream := &orderStream{ctx: ctx}
+
+	stream.onSend = func(event *OrderEvent) {
+		if len(stream.events) == chains*orders {
+			cancel()
+		}
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- server.WatchOrders(&WatchOrdersRequest{Key: watcher.Key}, stream)
+	}()
+
+	// wait for the stream subscribed
+	for {
+		broker.RLock()
+		subscribed := len(broker.subscribers)
+		broker.RUnlock()
+
+		if subscribed > 0 {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	// the chains notify concurrently, every event is streamed in the id order
+	var wg sync.WaitGroup
+
+	for i := 0; i < chains; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < orders; j++ {
+				require.NoError(t, broker.Notify(watcher, &sensors.Order{ID: "O_1", TX: "0x1", Status: sensors.StatusRunning}))
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	select {
+	case err := <-done:
+		require.Equal(t, context.Canceled, err)
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "stream events timeout", "streamed %d events", len(stream.events))
+	}
+
+	for i, event := range stream.events {
+		require.Equal(t, uint64(i+1), event.Cursor)
+	}
+}
@@ -1,6 +1,7 @@
 package cacher
 
 import (
+	"container/heap"
 	"sort"
 	"sync"
 	"time"
@@ -9,107 +10,299 @@ import (
 	sensors "github.com/laplacenetwork/eth-sensors"
 )
 
+// cacherImpl index orders by tx hash, pending and running orders are kept in a min-heap keyed by deadline block,
+// so mint is one map lookup and confirm only touches the orders reaching their deadline,
+// the orders with timeout duration are kept in another min-heap keyed by expire time
 type cacherImpl struct {
 	sync.Mutex
-	orders        []*sensors.Order
-	confirmBlocks int64
-	timeoutBlocks int64
+	orders         map[string]*entry
+	deadlines      *entryHeap
+	pending        *entryHeap
+	commits        *entryHeap
+	expires        *entryHeap
+	seq            uint64
+	confirmBlocks  int64
+	timeoutBlocks  int64
+	pendingTimeout time.Duration
+	confirmTimeout time.Duration
 }
 
-// New .
+// New create memory cacher with config:
+// order: confirmed/timeout blocks, pendingtimeout/confirmtimeout durations checked with block time, 0 to disable
 func New(config config.Config) (sensors.OrderCacher, error) {
 	return NewCacher(
 		int64(config.Get("order", "confirmed").Int(1)),
 		int64(config.Get("order", "timeout").Int(60)),
+		config.Get("order", "pendingtimeout").Duration(0),
+		config.Get("order", "confirmtimeout").Duration(0),
 	), nil
 }
 
 // NewCacher .
-func NewCacher(confirmBlocks int64, timeoutBlocks int64) sensors.OrderCacher {
-	return newCacher(confirmBlocks, timeoutBlocks)
+func NewCacher(confirmBlocks int64, timeoutBlocks int64, pendingTimeout time.Duration, confirmTimeout time.Duration) sensors.OrderCacher {
+	return newCacher(confirmBlocks, timeoutBlocks, pendingTimeout, confirmTimeout)
 }
 
-func newCacher(confirmBlocks int64, timeoutBlocks int64) sensors.OrderCacher {
+func newCacher(confirmBlocks int64, timeoutBlocks int64, pendingTimeout time.Duration, confirmTimeout time.Duration) sensors.OrderCacher {
 	return &cacherImpl{
-		confirmBlocks: confirmBlocks,
-		timeoutBlocks: timeoutBlocks,
+		orders:         make(map[string]*entry),
+		deadlines:      newDeadlineHeap(),
+		pending:        newPendingHeap(),
+		commits:        newCommitHeap(),
+		expires:        newExpireHeap(),
+		confirmBlocks:  confirmBlocks,
+		timeoutBlocks:  timeoutBlocks,
+		pendingTimeout: pendingTimeout,
+		confirmTimeout: confirmTimeout,
 	}
 }
 
+// deadline the first block the order timeout or confirmed, false if the order status never expire,
+// the depth chosen by policy and recorded on the order override the cacher default
+func (cacher *cacherImpl) deadline(order *sensors.Order) (int64, bool) {
+	switch order.Status {
+	case sensors.StatusPending:
+		return order.PendingBlock + depth(order.TimeoutDepth, cacher.timeoutBlocks) + 1, true
+	case sensors.StatusRunning:
+		return order.CommitBlock + depth(order.ConfirmDepth, cacher.confirmBlocks) + 1, true
+	}
+
+	return 0, false
+}
+
+// expire the block time after which the order timeout, false if no timeout duration,
+// the timeout seconds chosen by policy and recorded on the order override the cacher default
+func (cacher *cacherImpl) expire(order *sensors.Order) (time.Time, bool) {
+	var start time.Time
+	var timeout time.Duration
+
+	switch order.Status {
+	case sensors.StatusPending:
+		start, timeout = order.PendingTime, duration(order.PendingTimeout, cacher.pendingTimeout)
+	case sensors.StatusRunning:
+		start, timeout = order.CommitTime, duration(order.ConfirmTimeout, cacher.confirmTimeout)
+	}
+
+	if timeout <= 0 {
+		return time.Time{}, false
+	}
+
+	return start.Add(timeout), true
+}
+
+func duration(seconds int64, defaultDuration time.Duration) time.Duration {
+	if seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	return defaultDuration
+}
+
+func depth(order int64, defaultDepth int64) int64 {
+	if order > 0 {
+		return order
+	}
+
+	return defaultDepth
+}
+
+// put index the order, replace the cached order with the same tx
+func (cacher *cacherImpl) put(order *sensors.Order) {
+	if old, ok := cacher.orders[order.TX]; ok {
+		cacher.remove(old)
+	}
+
+	cacher.seq++
+
+	e := &entry{
+		order: order,
+		safe:  order.Status == sensors.StatusSafe,
+		seq:   cacher.seq,
+		index: [slots]int{-1, -1, -1, -1},
+	}
+
+	cacher.orders[order.TX] = e
+
+	cacher.index(e)
+}
+
+// index push the entry into the heaps its order status belong to
+func (cacher *cacherImpl) index(e *entry) {
+	if deadline, ok := cacher.deadline(e.order); ok {
+		e.deadline = deadline
+		heap.Push(cacher.deadlines, e)
+	}
+
+	if expire, ok := cacher.expire(e.order); ok {
+		e.expire = expire
+		heap.Push(cacher.expires, e)
+	}
+
+	switch e.order.Status {
+	case sensors.StatusPending:
+		heap.Push(cacher.pending, e)
+	case sensors.StatusIncluded, sensors.StatusSafe:
+		heap.Push(cacher.commits, e)
+	}
+}
+
+// unindex remove the entry from heaps
+func (cacher *cacherImpl) unindex(e *entry) {
+	if i := e.index[deadlineSlot]; i >= 0 {
+		heap.Remove(cacher.deadlines, i)
+	}
+
+	if i := e.index[pendingSlot]; i >= 0 {
+		heap.Remove(cacher.pending, i)
+	}
+
+	if i := e.index[commitSlot]; i >= 0 {
+		heap.Remove(cacher.commits, i)
+	}
+
+	if i := e.index[expireSlot]; i >= 0 {
+		heap.Remove(cacher.expires, i)
+	}
+}
+
+func (cacher *cacherImpl) remove(e *entry) {
+	cacher.unindex(e)
+	delete(cacher.orders, e.order.TX)
+}
+
 func (cacher *cacherImpl) Cache(orders []*sensors.Order) {
 	cacher.Lock()
 	defer cacher.Unlock()
 
-	cacher.orders = append(cacher.orders, orders...)
-
-	sort.Slice(cacher.orders, func(i, j int) bool {
-		return cacher.orders[i].PendingBlock < cacher.orders[j].PendingBlock
-	})
+	for _, order := range orders {
+		cacher.put(order)
+	}
 }
-func (cacher *cacherImpl) Mint(tx string, block int64, time time.Time) (*sensors.Order, bool) {
+
+func (cacher *cacherImpl) Mint(tx string, block int64, time time.Time) (*sensors.Order, *sensors.OrderHistory, bool) {
 
 	cacher.Lock()
 	defer cacher.Unlock()
 
-	for _, order := range cacher.orders {
-		if order.TX == tx {
-			order.CommitBlock = block
-			order.CommitTime = time
-			order.Status = sensors.StatusRunning
-			return order, true
-		}
+	e, ok := cacher.orders[tx]
+
+	if !ok {
+		return nil, nil, false
 	}
 
-	return nil, false
+	// the indexes depend on the status
+	cacher.unindex(e)
+	defer cacher.index(e)
+
+	history, err := e.order.Transit(sensors.StatusRunning, block, time, "")
+
+	if err != nil {
+		return nil, nil, false
+	}
+
+	e.order.CommitBlock = block
+	e.order.CommitTime = time
+
+	return e.order, history, true
 }
+
 func (cacher *cacherImpl) Confirm(block int64, time time.Time) (timeout []*sensors.Order, confirmed []*sensors.Order) {
 
 	cacher.Lock()
 	defer cacher.Unlock()
 
-	var orders []*sensors.Order
+	for {
+		e := cacher.deadlines.peek()
+
+		if e == nil || e.deadline > block {
+			break
+		}
+
+		cacher.remove(e)
 
-	for _, order := range cacher.orders {
+		if e.order.Status == sensors.StatusPending {
+			e.order.TimeoutReason = sensors.TimeoutBlock
+			timeout = append(timeout, e.order)
+		} else {
+			confirmed = append(confirmed, e.order)
+		}
+	}
 
-		if order.Status == sensors.StatusPending && block-order.PendingBlock > cacher.timeoutBlocks {
-			timeout = append(timeout, order)
-			continue
+	// the orders not reaching their deadline block in time
+	for {
+		e := cacher.expires.peek()
+
+		if e == nil || !e.expire.Before(time) {
+			return
 		}
 
-		if order.Status == sensors.StatusRunning && block-order.CommitBlock > cacher.confirmBlocks {
-			confirmed = append(confirmed, order)
-			continue
+		cacher.remove(e)
+
+		e.order.TimeoutReason = sensors.TimeoutTime
+		timeout = append(timeout, e.order)
+	}
+}
+
+func (cacher *cacherImpl) Finalize(safe int64, finalized int64) (safeOrders []*sensors.Order, finalizedOrders []*sensors.Order) {
+	cacher.Lock()
+	defer cacher.Unlock()
+
+	for {
+		e := cacher.commits.peek()
+
+		if e == nil || e.order.CommitBlock > finalized {
+			break
 		}
 
-		orders = append(orders, order)
+		cacher.remove(e)
+
+		finalizedOrders = append(finalizedOrders, e.order)
 	}
 
-	cacher.orders = orders
+	// the safe mark is not a heap key, mark in place
+	cacher.commits.walk(0, func(e *entry) bool {
+		return e.order.CommitBlock <= safe
+	}, func(e *entry) {
+		if !e.safe {
+			e.safe = true
+			safeOrders = append(safeOrders, e.order)
+		}
+	})
+
+	sort.Slice(safeOrders, func(i, j int) bool {
+		return safeOrders[i].CommitBlock < safeOrders[j].CommitBlock
+	})
 
 	return
 }
+
+// Pending get the pending order with the highest pending block
 func (cacher *cacherImpl) Pending() (*sensors.Order, bool) {
 
 	cacher.Lock()
 	defer cacher.Unlock()
 
-	if len(cacher.orders) == 0 {
+	e := cacher.pending.peek()
+
+	if e == nil {
 		return nil, false
 	}
 
-	order := cacher.orders[len(cacher.orders)-1]
+	return e.order, true
+}
 
-	if order.Status == sensors.StatusPending {
-		return order, true
-	}
+func (cacher *cacherImpl) Pend(order *sensors.Order) {
+	cacher.Lock()
+	defer cacher.Unlock()
 
-	return nil, false
+	cacher.put(order)
 }
-func (cacher *cacherImpl) Pend(order *sensors.Order) {
+
+func (cacher *cacherImpl) Size() int {
 	cacher.Lock()
 	defer cacher.Unlock()
 
-	cacher.orders = append(cacher.orders, order)
+	return len(cacher.orders)
 }
 
 func init() {
@@ -0,0 +1,263 @@
+package cacher
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirm(t *testing.T) {
+	cacher := NewCacher(2, 5, 0, 0)
+
+	cacher.Cache([]*sensors.Order{
+		{ID: "O_1", TX: "0x1", Status: sensors.StatusPending, PendingBlock: 10},
+		{ID: "O_2", TX: "0x2", Status: sensors.StatusRunning, PendingBlock: 10, CommitBlock: 10},
+	})
+
+	cacher.Pend(&sensors.Order{ID: "O_3", TX: "0x3", Status: sensors.StatusPending, PendingBlock: 11})
+	cacher.Pend(&sensors.Order{ID: "O_0", TX: "0x0", Status: sensors.StatusPending, PendingBlock: 9})
+
+	require.Equal(t, 4, cacher.Size())
+
+	// the pending order with the highest pending block whatever the insert order
+	order, ok := cacher.Pending()
+
+	require.True(t, ok)
+	require.Equal(t, "O_3", order.ID)
+
+	order, history, ok := cacher.Mint("0x3", 12, time.Now())
+
+	require.True(t, ok)
+	require.Equal(t, sensors.StatusRunning, order.Status)
+	require.Equal(t, sensors.StatusPending, history.From)
+	require.Equal(t, sensors.StatusRunning, history.To)
+	require.Equal(t, int64(12), history.Block)
+
+	order, ok = cacher.Pending()
+
+	require.True(t, ok)
+	require.Equal(t, "O_1", order.ID)
+
+	_, _, ok = cacher.Mint("0x4", 12, time.Now())
+
+	require.False(t, ok)
+
+	timeout, confirmed := cacher.Confirm(12, time.Now())
+
+	require.Empty(t, timeout)
+	require.Empty(t, confirmed)
+
+	timeout, confirmed = cacher.Confirm(15, time.Now())
+
+	require.Len(t, timeout, 1)
+	require.Equal(t, "O_0", timeout[0].ID)
+	require.Len(t, confirmed, 2)
+	require.Equal(t, "O_2", confirmed[0].ID)
+	require.Equal(t, "O_3", confirmed[1].ID)
+
+	timeout, confirmed = cacher.Confirm(16, time.Now())
+
+	require.Len(t, timeout, 1)
+	require.Equal(t, "O_1", timeout[0].ID)
+	require.Empty(t, confirmed)
+
+	require.Equal(t, 0, cacher.Size())
+
+	_, ok = cacher.Pending()
+
+	require.False(t, ok)
+}
+
+func TestPendReplace(t *testing.T) {
+	cacher := NewCacher(2, 5, 0, 0)
+
+	cacher.Pend(&sensors.Order{ID: "O_1", TX: "0x1", Status: sensors.StatusPending, PendingBlock: 10})
+	cacher.Pend(&sensors.Order{ID: "O_1", TX: "0x1", Status: sensors.StatusRunning, CommitBlock: 11})
+
+	require.Equal(t, 1, cacher.Size())
+
+	_, ok := cacher.Pending()
+
+	require.False(t, ok)
+
+	timeout, confirmed := cacher.Confirm(20, time.Now())
+
+	require.Empty(t, timeout)
+	require.Len(t, confirmed, 1)
+}
+
+func TestOrderDepth(t *testing.T) {
+	cacher := NewCacher(2, 5, 0, 0)
+
+	cacher.Cache([]*sensors.Order{
+		{ID: "O_1", TX: "0x1", Status: sensors.StatusRunning, CommitBlock: 10},
+		{ID: "O_2", TX: "0x2", Status: sensors.StatusRunning, CommitBlock: 10, ConfirmDepth: 12},
+		{ID: "O_3", TX: "0x3", Status: sensors.StatusPending, PendingBlock: 10, TimeoutDepth: 1},
+	})
+
+	timeout, confirmed := cacher.Confirm(13, time.Now())
+
+	require.Len(t, timeout, 1)
+	require.Equal(t, "O_3", timeout[0].ID)
+	require.Len(t, confirmed, 1)
+	require.Equal(t, "O_1", confirmed[0].ID)
+
+	timeout, confirmed = cacher.Confirm(22, time.Now())
+
+	require.Empty(t, timeout)
+	require.Empty(t, confirmed)
+
+	_, confirmed = cacher.Confirm(23, time.Now())
+
+	require.Len(t, confirmed, 1)
+	require.Equal(t, "O_2", confirmed[0].ID)
+}
+
+func TestTimeout(t *testing.T) {
+	cacher := NewCacher(2, 5, time.Minute, 2*time.Minute)
+
+	start := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	cacher.Cache([]*sensors.Order{
+		{ID: "O_1", TX: "0x1", Status: sensors.StatusPending, PendingBlock: 10, PendingTime: start},
+		{ID: "O_2", TX: "0x2", Status: sensors.StatusRunning, CommitBlock: 10, CommitTime: start},
+		{ID: "O_3", TX: "0x3", Status: sensors.StatusRunning, CommitBlock: 10, CommitTime: start, ConfirmTimeout: 30},
+		{ID: "O_4", TX: "0x4", Status: sensors.StatusPending, PendingBlock: 12, PendingTime: start},
+	})
+
+	timeout, confirmed := cacher.Confirm(11, start.Add(time.Minute))
+
+	require.Len(t, timeout, 1)
+	require.Equal(t, "O_3", timeout[0].ID)
+	require.Equal(t, sensors.TimeoutTime, timeout[0].TimeoutReason)
+	require.Empty(t, confirmed)
+
+	timeout, confirmed = cacher.Confirm(12, start.Add(time.Minute+time.Second))
+
+	require.Len(t, timeout, 2)
+	require.Equal(t, "O_1", timeout[0].ID)
+	require.Equal(t, "O_4", timeout[1].ID)
+	require.Equal(t, sensors.TimeoutTime, timeout[1].TimeoutReason)
+	require.Empty(t, confirmed)
+
+	// the deadline block reached before the expire time
+	timeout, confirmed = cacher.Confirm(13, start.Add(time.Minute+time.Second))
+
+	require.Empty(t, timeout)
+	require.Len(t, confirmed, 1)
+	require.Equal(t, "O_2", confirmed[0].ID)
+
+	cacher.Pend(&sensors.Order{ID: "O_5", TX: "0x5", Status: sensors.StatusPending, PendingBlock: 13, PendingTime: start.Add(time.Hour)})
+
+	timeout, _ = cacher.Confirm(19, start.Add(time.Hour))
+
+	require.Len(t, timeout, 1)
+	require.Equal(t, sensors.TimeoutBlock, timeout[0].TimeoutReason)
+}
+
+func TestFinalize(t *testing.T) {
+	cacher := NewCacher(2, 5, 0, 0)
+
+	cacher.Cache([]*sensors.Order{
+		{ID: "O_1", TX: "0x1", Status: sensors.StatusIncluded, CommitBlock: 10},
+		{ID: "O_2", TX: "0x2", Status: sensors.StatusIncluded, CommitBlock: 12},
+		{ID: "O_3", TX: "0x3", Status: sensors.StatusSafe, CommitBlock: 11},
+		{ID: "O_4", TX: "0x4", Status: sensors.StatusPending, PendingBlock: 10},
+	})
+
+	// included orders never expire by depth, pending orders still timeout
+	timeout, confirmed := cacher.Confirm(100, time.Now())
+
+	require.Len(t, timeout, 1)
+	require.Empty(t, confirmed)
+
+	safe, finalized := cacher.Finalize(12, 10)
+
+	require.Len(t, safe, 1)
+	require.Equal(t, "O_2", safe[0].ID)
+	require.Equal(t, sensors.StatusIncluded, safe[0].Status)
+	require.Len(t, finalized, 1)
+	require.Equal(t, "O_1", finalized[0].ID)
+
+	safe, finalized = cacher.Finalize(12, 12)
+
+	require.Empty(t, safe)
+	require.Len(t, finalized, 2)
+	require.Equal(t, "O_3", finalized[0].ID)
+	require.Equal(t, "O_2", finalized[1].ID)
+
+	require.Equal(t, 0, cacher.Size())
+}
+
+// newFilledCacher cache n running orders, the commit blocks spread over n/ordersPerBlock blocks
+func newFilledCacher(n int, ordersPerBlock int) sensors.OrderCacher {
+	cacher := NewCacher(12, 60, 0, 0)
+
+	orders := make([]*sensors.Order, n)
+
+	for i := range orders {
+		orders[i] = &sensors.Order{
+			ID:          fmt.Sprintf("O_%d", i),
+			TX:          fmt.Sprintf("0x%x", i),
+			Status:      sensors.StatusRunning,
+			CommitBlock: int64(i / ordersPerBlock),
+		}
+	}
+
+	cacher.Cache(orders)
+
+	return cacher
+}
+
+// BenchmarkConfirm every block confirm the orders of one block and cache the same number of new orders,
+// the cached orders stay at 100k
+func BenchmarkConfirm(b *testing.B) {
+	const size = 100000
+	const ordersPerBlock = 10
+
+	cacher := newFilledCacher(size, ordersPerBlock)
+
+	next := size
+	now := time.Now()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		block := int64(i) + 12 + 1
+
+		cacher.Confirm(block, now)
+
+		for j := 0; j < ordersPerBlock; j++ {
+			cacher.Pend(&sensors.Order{
+				TX:          fmt.Sprintf("0x%x", next),
+				Status:      sensors.StatusRunning,
+				CommitBlock: int64(next / ordersPerBlock),
+			})
+
+			next++
+		}
+	}
+}
+
+func BenchmarkMint(b *testing.B) {
+	const size = 100000
+
+	cacher := newFilledCacher(size, 10)
+
+	txs := make([]string, size)
+
+	for i := range txs {
+		txs[i] = fmt.Sprintf("0x%x", i)
+	}
+
+	now := time.Now()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		cacher.Mint(txs[i%size], int64(i), now)
+	}
+}
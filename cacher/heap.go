@@ -0,0 +1,135 @@
+package cacher
+
+import (
+	"time"
+
+	sensors "github.com/laplacenetwork/eth-sensors"
+)
+
+// heap slots of the entry
+const (
+	deadlineSlot = iota // all pending and running orders ordered by deadline block
+	pendingSlot         // pending orders ordered by pending block desc
+	commitSlot          // included and safe orders ordered by commit block
+	expireSlot          // pending and running orders with timeout duration ordered by expire time
+	slots
+)
+
+// entry the cached order with its heap positions
+type entry struct {
+	order    *sensors.Order
+	deadline int64      // the first block the order timeout or confirmed
+	expire   time.Time  // the block time after which the order timeout
+	safe     bool       // the included order is reported safe
+	seq      uint64     // insert sequence, keep the order of equal keys stable
+	index    [slots]int // position in each heap, -1 if not in the heap
+}
+
+// entryHeap implement heap.Interface for one slot
+type entryHeap struct {
+	slot    int
+	entries []*entry
+	less    func(a, b *entry) bool
+}
+
+func newDeadlineHeap() *entryHeap {
+	return &entryHeap{
+		slot: deadlineSlot,
+		less: func(a, b *entry) bool {
+			if a.deadline != b.deadline {
+				return a.deadline < b.deadline
+			}
+
+			return a.seq < b.seq
+		},
+	}
+}
+
+func newPendingHeap() *entryHeap {
+	return &entryHeap{
+		slot: pendingSlot,
+		less: func(a, b *entry) bool {
+			if a.order.PendingBlock != b.order.PendingBlock {
+				return a.order.PendingBlock > b.order.PendingBlock
+			}
+
+			return a.seq > b.seq
+		},
+	}
+}
+
+func newCommitHeap() *entryHeap {
+	return &entryHeap{
+		slot: commitSlot,
+		less: func(a, b *entry) bool {
+			if a.order.CommitBlock != b.order.CommitBlock {
+				return a.order.CommitBlock < b.order.CommitBlock
+			}
+
+			return a.seq < b.seq
+		},
+	}
+}
+
+func newExpireHeap() *entryHeap {
+	return &entryHeap{
+		slot: expireSlot,
+		less: func(a, b *entry) bool {
+			if !a.expire.Equal(b.expire) {
+				return a.expire.Before(b.expire)
+			}
+
+			return a.seq < b.seq
+		},
+	}
+}
+
+func (h *entryHeap) Len() int {
+	return len(h.entries)
+}
+
+func (h *entryHeap) Less(i, j int) bool {
+	return h.less(h.entries[i], h.entries[j])
+}
+
+func (h *entryHeap) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.entries[i].index[h.slot] = i
+	h.entries[j].index[h.slot] = j
+}
+
+func (h *entryHeap) Push(x interface{}) {
+	e := x.(*entry)
+	e.index[h.slot] = len(h.entries)
+	h.entries = append(h.entries, e)
+}
+
+func (h *entryHeap) Pop() interface{} {
+	n := len(h.entries)
+	e := h.entries[n-1]
+	h.entries[n-1] = nil
+	h.entries = h.entries[:n-1]
+	e.index[h.slot] = -1
+
+	return e
+}
+
+func (h *entryHeap) peek() *entry {
+	if len(h.entries) == 0 {
+		return nil
+	}
+
+	return h.entries[0]
+}
+
+// walk visit the entries not greater than max in heap order
+func (h *entryHeap) walk(i int, max func(e *entry) bool, visit func(e *entry)) {
+	if i >= len(h.entries) || !max(h.entries[i]) {
+		return
+	}
+
+	visit(h.entries[i])
+
+	h.walk(2*i+1, max, visit)
+	h.walk(2*i+2, max, visit)
+}
@@ -0,0 +1,46 @@
+package sensors
+
+import (
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// IDGenerator the order and watcher id generator
+type IDGenerator interface {
+	// generate unique id with prefix
+	Generate(prefix string) string
+}
+
+// Clock the time source of sensor
+type Clock interface {
+	Now() time.Time
+}
+
+type snowflakeGenerator struct {
+	node *snowflake.Node
+}
+
+// NewSnowflake create snowflake id generator with node number
+func NewSnowflake(node int64) (IDGenerator, error) {
+	snode, err := snowflake.NewNode(node)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &snowflakeGenerator{node: snode}, nil
+}
+
+func (generator *snowflakeGenerator) Generate(prefix string) string {
+	return prefix + generator.node.Generate().String()
+}
+
+type systemClock struct{}
+
+func (clock systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock the wall clock
+var SystemClock Clock = systemClock{}
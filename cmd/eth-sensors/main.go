@@ -0,0 +1,188 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	config "github.com/dynamicgo/go-config"
+	"github.com/dynamicgo/go-config/source/file"
+	"github.com/dynamicgo/slf4go"
+	"github.com/go-xorm/xorm"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/laplacenetwork/eth-sensors/api"
+	"github.com/laplacenetwork/eth-sensors/cacher"
+	schema "github.com/laplacenetwork/eth-sensors/db"
+	"github.com/laplacenetwork/eth-sensors/metrics"
+	"github.com/laplacenetwork/eth-sensors/rediscacher"
+	"google.golang.org/grpc"
+
+	_ "github.com/laplacenetwork/eth-sensors/core"
+	_ "github.com/laplacenetwork/eth-sensors/oracle"
+	_ "github.com/laplacenetwork/eth-sensors/source"
+	_ "github.com/laplacenetwork/eth-sensors/storage"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var logger = slf4go.Get("eth-sensors")
+
+var configPath = flag.String("config", "./conf/sensor.json", "sensor config file path")
+var autoMigrate = flag.Bool("migrate", true, "apply the database migrations before start")
+
+type logNotifier struct {
+	slf4go.Logger
+}
+
+func (notifier *logNotifier) Notify(receiver *sensors.Watcher, order *sensors.Order) error {
+	notifier.InfoF("watcher %s order %s tx %s status %s", receiver.Key, order.ID, order.TX, order.Status)
+	return nil
+}
+
+// openDB open the database shared by the sensor and the api broker
+func openDB(config config.Config) (*xorm.Engine, error) {
+	driver := config.Get("database", "driver").String("sqlite3")
+	source := config.Get("database", "source").String("../.build/sensors.db")
+
+	return xorm.NewEngine(driver, source)
+}
+
+func run() error {
+	flag.Parse()
+
+	conf := config.NewConfig()
+
+	if err := conf.Load(file.NewSource(file.WithPath(*configPath))); err != nil {
+		return fmt.Errorf("load config %s err: %s", *configPath, err)
+	}
+
+	db, err := openDB(conf)
+
+	if err != nil {
+		return fmt.Errorf("open database err: %s", err)
+	}
+
+	defer db.Close()
+
+	if *autoMigrate {
+		if err := schema.Migrate(db, schema.Latest()); err != nil {
+			return fmt.Errorf("migrate database err: %s", err)
+		}
+	}
+
+	clock := sensors.SystemClock
+
+	var broker *api.Broker
+
+	notifierF := func(config config.Config) (sensors.Notifier, error) {
+		driver := config.Get("driver").String("api")
+
+		switch driver {
+		case "api":
+			broker = api.NewBroker(db, clock, config)
+			return broker, nil
+		case "log":
+			return &logNotifier{Logger: slf4go.Get("log-notifier")}, nil
+		default:
+			return nil, fmt.Errorf("unknown notifier driver %s", driver)
+		}
+	}
+
+	cacherF := func(config config.Config) (sensors.OrderCacher, error) {
+		driver := config.Get("driver").String("memory")
+
+		switch driver {
+		case "memory":
+			return cacher.New(config)
+		case "redis":
+			return rediscacher.New(config)
+		default:
+			return nil, fmt.Errorf("unknown cacher driver %s", driver)
+		}
+	}
+
+	sensor, err := sensors.New(
+		conf,
+		sensors.WithDB(db),
+		sensors.WithNotifier(notifierF),
+		sensors.WithCacher(cacherF),
+		sensors.WithClock(clock),
+	)
+
+	if err != nil {
+		return fmt.Errorf("create sensor err: %s", err)
+	}
+
+	addr := conf.Get("api", "addr").String(":7000")
+
+	listener, err := net.Listen("tcp", addr)
+
+	if err != nil {
+		return fmt.Errorf("listen api %s err: %s", addr, err)
+	}
+
+	server := grpc.NewServer()
+
+	api.NewServer(sensor, broker).Register(server)
+
+	go func() {
+		logger.InfoF("serve api on %s", addr)
+
+		if err := server.Serve(listener); err != nil {
+			logger.ErrorF("serve api err: %s", err)
+		}
+	}()
+
+	metricsAddr := conf.Get("metrics", "addr").String(":7001")
+
+	mux := http.NewServeMux()
+
+	mux.Handle("/metrics", metrics.Handler())
+
+	api.NewHealth(sensor, clock, conf).Register(mux)
+
+	httpServer := &http.Server{
+		Addr:    metricsAddr,
+		Handler: mux,
+	}
+
+	go func() {
+		logger.InfoF("serve metrics and health on %s", metricsAddr)
+
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.ErrorF("serve metrics err: %s", err)
+		}
+	}()
+
+	signals := make(chan os.Signal, 1)
+
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	sig := <-signals
+
+	logger.InfoF("receive signal %s, stop eth sensors", sig)
+
+	server.GracefulStop()
+
+	// the other replicas take over the released lease at once
+	if closable, ok := sensor.(sensors.ClosableSensor); ok {
+		if err := closable.Close(); err != nil {
+			logger.ErrorF("close sensor err: %s", err)
+		}
+	}
+
+	httpServer.Close()
+
+	return nil
+}
+
+func main() {
+	if err := run(); err != nil {
+		logger.ErrorF("%s", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	config "github.com/dynamicgo/go-config"
+	"github.com/dynamicgo/xorm-decorator"
+	"github.com/go-xorm/xorm"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/laplacenetwork/eth-sensors/api"
+	"google.golang.org/grpc"
+)
+
+// backend the sensorsctl operation backend
+type backend interface {
+	NewWatcher(watcher *sensors.Watcher) (string, error)
+	DeleteWatcher(key string) error
+	ListWatchers(offset int64, size int64) ([]*sensors.Watcher, int64, error)
+	GetOrder(tx string) (*sensors.Order, error)
+	RecheckOrder(tx string) (*sensors.Order, error)
+	OrderHistory(tx string) ([]*sensors.OrderHistory, error)
+	Rewind(chainID int64, block int64) error
+	Replay(chainID int64, from int64, to int64) (int64, error)
+}
+
+type grpcBackend struct {
+	client  api.SensorsClient
+	timeout time.Duration
+}
+
+func newGRPCBackend(addr string, timeout time.Duration) (backend, error) {
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &grpcBackend{
+		client:  api.NewSensorsClient(conn),
+		timeout: timeout,
+	}, nil
+}
+
+func (backend *grpcBackend) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), backend.timeout)
+}
+
+func (backend *grpcBackend) NewWatcher(watcher *sensors.Watcher) (string, error) {
+	ctx, cancel := backend.context()
+	defer cancel()
+
+	resp, err := backend.client.NewWatcher(ctx, &api.NewWatcherRequest{
+		Watcher: &api.Watcher{
+			Name:           watcher.Name,
+			Key:            watcher.Key,
+			ChainId:        watcher.ChainID,
+			Address:        watcher.Address,
+			Erc20:          watcher.ERC20,
+			Confirmed:      watcher.Confirmed,
+			Timeout:        watcher.Timeout,
+			PendingTimeout: watcher.PendingTimeout,
+			ConfirmTimeout: watcher.ConfirmTimeout,
+		},
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return resp.Id, nil
+}
+
+func (backend *grpcBackend) DeleteWatcher(key string) error {
+	ctx, cancel := backend.context()
+	defer cancel()
+
+	_, err := backend.client.DeleteWatcher(ctx, &api.DeleteWatcherRequest{Key: key})
+
+	return err
+}
+
+func (backend *grpcBackend) ListWatchers(offset int64, size int64) ([]*sensors.Watcher, int64, error) {
+	ctx, cancel := backend.context()
+	defer cancel()
+
+	resp, err := backend.client.ListWatchers(ctx, &api.ListWatchersRequest{Offset: offset, Size: size})
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var watchers []*sensors.Watcher
+
+	for _, watcher := range resp.Watchers {
+		watchers = append(watchers, &sensors.Watcher{
+			ID:             watcher.Id,
+			Name:           watcher.Name,
+			Key:            watcher.Key,
+			ChainID:        watcher.ChainId,
+			Address:        watcher.Address,
+			ERC20:          watcher.Erc20,
+			Confirmed:      watcher.Confirmed,
+			Timeout:        watcher.Timeout,
+			PendingTimeout: watcher.PendingTimeout,
+			ConfirmTimeout: watcher.ConfirmTimeout,
+		})
+	}
+
+	return watchers, resp.Total, nil
+}
+
+func (backend *grpcBackend) GetOrder(tx string) (*sensors.Order, error) {
+	ctx, cancel := backend.context()
+	defer cancel()
+
+	order, err := backend.client.GetOrder(ctx, &api.GetOrderRequest{Tx: tx})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return fromOrderPB(order), nil
+}
+
+func (backend *grpcBackend) RecheckOrder(tx string) (*sensors.Order, error) {
+	ctx, cancel := backend.context()
+	defer cancel()
+
+	order, err := backend.client.RecheckOrder(ctx, &api.RecheckOrderRequest{Tx: tx})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return fromOrderPB(order), nil
+}
+
+func (backend *grpcBackend) OrderHistory(tx string) ([]*sensors.OrderHistory, error) {
+	ctx, cancel := backend.context()
+	defer cancel()
+
+	resp, err := backend.client.GetOrderHistory(ctx, &api.GetOrderHistoryRequest{Tx: tx})
+
+	if err != nil {
+		return nil, err
+	}
+
+	histories := make([]*sensors.OrderHistory, 0, len(resp.Histories))
+
+	for _, history := range resp.Histories {
+		histories = append(histories, &sensors.OrderHistory{
+			OrderID: history.OrderId,
+			TX:      history.Tx,
+			From:    sensors.Status(history.From),
+			To:      sensors.Status(history.To),
+			Block:   history.Block,
+			Time:    time.Unix(history.Time, 0),
+			Reason:  history.Reason,
+		})
+	}
+
+	return histories, nil
+}
+
+func (backend *grpcBackend) Rewind(chainID int64, block int64) error {
+	ctx, cancel := backend.context()
+	defer cancel()
+
+	_, err := backend.client.Rewind(ctx, &api.RewindRequest{ChainId: chainID, Block: block})
+
+	return err
+}
+
+func (backend *grpcBackend) Replay(chainID int64, from int64, to int64) (int64, error) {
+	ctx, cancel := backend.context()
+	defer cancel()
+
+	resp, err := backend.client.ReplayOrders(ctx, &api.ReplayOrdersRequest{ChainId: chainID, From: from, To: to})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Replayed, nil
+}
+
+func fromOrderPB(order *api.Order) *sensors.Order {
+	return &sensors.Order{
+		ID:             order.Id,
+		ChainID:        order.ChainId,
+		TX:             order.Tx,
+		PendingBlock:   order.PendingBlock,
+		CommitBlock:    order.CommitBlock,
+		ConfirmBlock:   order.ConfirmBlock,
+		Status:         sensors.Status(order.Status),
+		CreateTime:     time.Unix(order.CreateTime, 0),
+		PendingTime:    time.Unix(order.PendingTime, 0),
+		CommitTime:     time.Unix(order.CommitTime, 0),
+		ConfirmTime:    time.Unix(order.ConfirmTime, 0),
+		From:           order.From,
+		To:             order.To,
+		Value:          order.Value,
+		Code:           order.Code,
+		GasLimits:      order.GasLimits,
+		GasPrice:       order.GasPrice,
+		ConfirmDepth:   order.ConfirmDepth,
+		TimeoutDepth:   order.TimeoutDepth,
+		PendingTimeout: order.PendingTimeout,
+		ConfirmTimeout: order.ConfirmTimeout,
+		TimeoutReason:  sensors.TimeoutReason(order.TimeoutReason),
+		FailureReason:  sensors.FailureReason(order.FailureReason),
+		RevertReason:   order.RevertReason,
+		Version:        order.Version,
+		Token:          order.Token,
+		Symbol:         order.Symbol,
+		Amount:         order.Amount,
+		FiatValue:      order.FiatValue,
+		FiatCurrency:   order.FiatCurrency,
+		Archived:       order.Archived,
+	}
+}
+
+// dbBackend operate the sensor database directly, only support watcher, order query and rewind operations
+type dbBackend struct {
+	db  *xorm.Engine
+	ids sensors.IDGenerator
+}
+
+func newDBBackend(config config.Config) (backend, error) {
+	db, err := xorm.NewEngine(
+		config.Get("database", "driver").String("sqlite3"),
+		config.Get("database", "source").String("../.build/sensors.db"),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := sensors.NewSnowflake(int64(config.Get("snode").Int(4)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &dbBackend{
+		db:  db,
+		ids: ids,
+	}, nil
+}
+
+func (backend *dbBackend) NewWatcher(watcher *sensors.Watcher) (string, error) {
+	watcher.ID = backend.ids.Generate("W_")
+
+	watcher.Address = strings.ToLower(watcher.Address)
+
+	_, err := backend.db.InsertOne(watcher)
+
+	if err != nil {
+		if decorator.DuplicateKey(backend.db, err) {
+			return "", sensors.ErrWatcherExists
+		}
+
+		return "", err
+	}
+
+	return watcher.ID, nil
+}
+
+func (backend *dbBackend) DeleteWatcher(key string) error {
+	_, err := backend.db.Where(`"key" = ?`, key).Delete(new(sensors.Watcher))
+
+	return err
+}
+
+func (backend *dbBackend) ListWatchers(offset int64, size int64) ([]*sensors.Watcher, int64, error) {
+	watchers := make([]*sensors.Watcher, 0)
+
+	c, err := backend.db.Limit(int(size), int(offset)).FindAndCount(&watchers)
+
+	return watchers, c, err
+}
+
+func (backend *dbBackend) GetOrder(tx string) (*sensors.Order, error) {
+	var order sensors.Order
+
+	ok, err := backend.db.Where(`"t_x" = ?`, strings.ToLower(tx)).Get(&order)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return backend.getArchived(tx)
+	}
+
+	return &order, nil
+}
+
+// getArchived get the order moved to the archive by the retention policy
+func (backend *dbBackend) getArchived(tx string) (*sensors.Order, error) {
+	var archived sensors.ArchivedOrder
+
+	ok, err := backend.db.Where(`"t_x" = ?`, strings.ToLower(tx)).Get(&archived)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, sensors.ErrOrderNotFound
+	}
+
+	archived.Order.Archived = true
+
+	return &archived.Order, nil
+}
+
+func (backend *dbBackend) OrderHistory(tx string) ([]*sensors.OrderHistory, error) {
+	order, err := backend.GetOrder(tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	histories := make([]*sensors.OrderHistory, 0)
+
+	err = backend.db.Where(`"order_i_d" = ?`, order.ID).Asc("i_d").Find(&histories)
+
+	return histories, err
+}
+
+func (backend *dbBackend) RecheckOrder(tx string) (*sensors.Order, error) {
+	return nil, fmt.Errorf("recheck order expect a running sensor, use -addr instead of -config")
+}
+
+// Rewind move the chain cursor to the block before block, the block sources resume from block after the sensors
+// restarted, the sensors must be stopped or the running leader overwrites the cursor
+func (backend *dbBackend) Rewind(chainID int64, block int64) error {
+	lease := new(sensors.Lease)
+
+	ok, err := backend.db.Where(`"expire" > ?`, time.Now().UnixNano()/int64(time.Millisecond)).Get(lease)
+
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		return fmt.Errorf("replica %s holds lease %s, stop the sensors or use -addr instead of -config", lease.Holder, lease.Name)
+	}
+
+	cursor := &sensors.Cursor{
+		ChainID:    chainID,
+		Block:      block - 1,
+		UpdateTime: time.Now(),
+	}
+
+	affected, err := backend.db.Where(`"chain_i_d" = ?`, chainID).Cols("block", "hash", "update_time").Update(cursor)
+
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("chain %d has no cursor to rewind", chainID)
+	}
+
+	return nil
+}
+
+func (backend *dbBackend) Replay(chainID int64, from int64, to int64) (int64, error) {
+	return 0, fmt.Errorf("replay expect a running sensor, use -addr instead of -config")
+}
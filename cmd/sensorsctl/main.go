@@ -0,0 +1,318 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	config "github.com/dynamicgo/go-config"
+	"github.com/dynamicgo/go-config/source/file"
+	"github.com/go-xorm/xorm"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	schema "github.com/laplacenetwork/eth-sensors/db"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const usage = `usage: sensorsctl [flags] <command> [args]
+
+commands:
+  watcher add -key <key> -address <address> [-chain <chain id>] [-name <name>] [-erc20] [-confirmed <blocks>] [-timeout <blocks>] [-pending-timeout <duration>] [-confirm-timeout <duration>]
+  watcher remove <key>
+  watcher list [-offset <offset>] [-size <size>]
+  order get <tx>
+  order recheck <tx>
+  order history <tx>
+  rewind <block> (with -config the sensors must be stopped)
+  replay <from block> <to block>
+  migrate status|up [version]|down <version> (with -config only)
+
+flags:
+`
+
+var (
+	addr       = flag.String("addr", "localhost:7000", "sensor grpc api address")
+	configPath = flag.String("config", "", "operate sensor database directly with the sensor config file")
+	timeout    = flag.Duration("timeout", 10*time.Second, "grpc call timeout")
+	chainID    = flag.Int64("chain", 1, "chain id of rewind and replay commands")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "sensorsctl: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func newBackend() (backend, error) {
+	if *configPath == "" {
+		return newGRPCBackend(*addr, *timeout)
+	}
+
+	conf := config.NewConfig()
+
+	if err := conf.Load(file.NewSource(file.WithPath(*configPath))); err != nil {
+		return nil, err
+	}
+
+	return newDBBackend(conf)
+}
+
+func run(args []string) error {
+	if args[0] == "migrate" {
+		return migrateCommand(args[1:])
+	}
+
+	backend, err := newBackend()
+
+	if err != nil {
+		return err
+	}
+
+	return command(backend, args)
+}
+
+// command run the sensor operation command with backend
+func command(backend backend, args []string) error {
+	switch args[0] {
+	case "watcher":
+		return watcherCommand(backend, args[1:])
+	case "order":
+		return orderCommand(backend, args[1:])
+	case "rewind":
+		if len(args) != 2 {
+			return fmt.Errorf("expect rewind <block>")
+		}
+
+		block, err := strconv.ParseInt(args[1], 10, 64)
+
+		if err != nil {
+			return err
+		}
+
+		return backend.Rewind(*chainID, block)
+	case "replay":
+		if len(args) != 3 {
+			return fmt.Errorf("expect replay <from block> <to block>")
+		}
+
+		from, err := strconv.ParseInt(args[1], 10, 64)
+
+		if err != nil {
+			return err
+		}
+
+		to, err := strconv.ParseInt(args[2], 10, 64)
+
+		if err != nil {
+			return err
+		}
+
+		replayed, err := backend.Replay(*chainID, from, to)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("replayed %d orders\n", replayed)
+
+		return nil
+	default:
+		return fmt.Errorf("unknown command %s", args[0])
+	}
+}
+
+func watcherCommand(backend backend, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expect watcher add|remove|list")
+	}
+
+	switch args[0] {
+	case "add":
+		flags := flag.NewFlagSet("watcher add", flag.ExitOnError)
+		key := flags.String("key", "", "watcher unique key")
+		name := flags.String("name", "", "watcher name")
+		address := flags.String("address", "", "watched address")
+		erc20 := flags.Bool("erc20", false, "the address is a erc20 contract address")
+		chain := flags.Int64("chain", 1, "chain id of watched address")
+		confirmed := flags.Int64("confirmed", 0, "confirmation blocks required by the watcher")
+		timeout := flags.Int64("timeout", 0, "pending timeout blocks required by the watcher")
+		pendingTimeout := flags.Duration("pending-timeout", 0, "pending timeout duration required by the watcher")
+		confirmTimeout := flags.Duration("confirm-timeout", 0, "confirmation timeout duration since commit required by the watcher")
+
+		flags.Parse(args[1:])
+
+		if *key == "" || *address == "" {
+			return fmt.Errorf("expect -key and -address")
+		}
+
+		id, err := backend.NewWatcher(&sensors.Watcher{
+			Key:            *key,
+			Name:           *name,
+			ChainID:        *chain,
+			Address:        *address,
+			ERC20:          *erc20,
+			Confirmed:      *confirmed,
+			Timeout:        *timeout,
+			PendingTimeout: int64(pendingTimeout.Seconds()),
+			ConfirmTimeout: int64(confirmTimeout.Seconds()),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(id)
+
+		return nil
+	case "remove":
+		if len(args) != 2 {
+			return fmt.Errorf("expect watcher remove <key>")
+		}
+
+		return backend.DeleteWatcher(args[1])
+	case "list":
+		flags := flag.NewFlagSet("watcher list", flag.ExitOnError)
+		offset := flags.Int64("offset", 0, "list offset")
+		size := flags.Int64("size", 100, "list size")
+
+		flags.Parse(args[1:])
+
+		watchers, total, err := backend.ListWatchers(*offset, *size)
+
+		if err != nil {
+			return err
+		}
+
+		return printJSON(map[string]interface{}{
+			"total":    total,
+			"watchers": watchers,
+		})
+	default:
+		return fmt.Errorf("unknown watcher command %s", args[0])
+	}
+}
+
+func orderCommand(backend backend, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expect order get|recheck|history <tx>")
+	}
+
+	if args[0] == "history" {
+		histories, err := backend.OrderHistory(args[1])
+
+		if err != nil {
+			return err
+		}
+
+		return printJSON(histories)
+	}
+
+	var (
+		order *sensors.Order
+		err   error
+	)
+
+	switch args[0] {
+	case "get":
+		order, err = backend.GetOrder(args[1])
+	case "recheck":
+		order, err = backend.RecheckOrder(args[1])
+	default:
+		return fmt.Errorf("unknown order command %s", args[0])
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return printJSON(order)
+}
+
+func migrateCommand(args []string) error {
+	if *configPath == "" {
+		return fmt.Errorf("expect -config to migrate the sensor database")
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("expect migrate status|up|down")
+	}
+
+	conf := config.NewConfig()
+
+	if err := conf.Load(file.NewSource(file.WithPath(*configPath))); err != nil {
+		return err
+	}
+
+	db, err := xorm.NewEngine(
+		conf.Get("database", "driver").String("sqlite3"),
+		conf.Get("database", "source").String("../.build/sensors.db"),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	switch args[0] {
+	case "status":
+		current, err := schema.Current(db)
+
+		if err != nil {
+			return err
+		}
+
+		return printJSON(map[string]interface{}{
+			"current": current,
+			"latest":  schema.Latest(),
+		})
+	case "up":
+		version := schema.Latest()
+
+		if len(args) == 2 {
+			if version, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+				return err
+			}
+		}
+
+		return schema.Migrate(db, version)
+	case "down":
+		if len(args) != 2 {
+			return fmt.Errorf("expect migrate down <version>")
+		}
+
+		version, err := strconv.ParseInt(args[1], 10, 64)
+
+		if err != nil {
+			return err
+		}
+
+		return schema.Migrate(db, version)
+	default:
+		return fmt.Errorf("unknown migrate command %s", args[0])
+	}
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
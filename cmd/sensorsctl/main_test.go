@@ -0,0 +1,157 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-xorm/xorm"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	schema "github.com/laplacenetwork/eth-sensors/db"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// fakeBackend record the operations
+type fakeBackend struct {
+	watchers []*sensors.Watcher
+	deleted  []string
+	orders   map[string]*sensors.Order
+	rechecks []string
+	rewinds  [][2]int64
+	replays  [][3]int64
+}
+
+func (backend *fakeBackend) NewWatcher(watcher *sensors.Watcher) (string, error) {
+	backend.watchers = append(backend.watchers, watcher)
+	return "W_1", nil
+}
+
+func (backend *fakeBackend) DeleteWatcher(key string) error {
+	backend.deleted = append(backend.deleted, key)
+	return nil
+}
+
+func (backend *fakeBackend) ListWatchers(offset int64, size int64) ([]*sensors.Watcher, int64, error) {
+	return backend.watchers, int64(len(backend.watchers)), nil
+}
+
+func (backend *fakeBackend) GetOrder(tx string) (*sensors.Order, error) {
+	order, ok := backend.orders[tx]
+
+	if !ok {
+		return nil, sensors.ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+func (backend *fakeBackend) RecheckOrder(tx string) (*sensors.Order, error) {
+	backend.rechecks = append(backend.rechecks, tx)
+	return backend.GetOrder(tx)
+}
+
+func (backend *fakeBackend) OrderHistory(tx string) ([]*sensors.OrderHistory, error) {
+	if _, err := backend.GetOrder(tx); err != nil {
+		return nil, err
+	}
+
+	return []*sensors.OrderHistory{{TX: tx, From: sensors.StatusCreated, To: sensors.StatusRunning}}, nil
+}
+
+func (backend *fakeBackend) Rewind(chainID int64, block int64) error {
+	backend.rewinds = append(backend.rewinds, [2]int64{chainID, block})
+	return nil
+}
+
+func (backend *fakeBackend) Replay(chainID int64, from int64, to int64) (int64, error) {
+	backend.replays = append(backend.replays, [3]int64{chainID, from, to})
+	return 2, nil
+}
+
+func TestCommand(t *testing.T) {
+	backend := &fakeBackend{
+		orders: map[string]*sensors.Order{
+			"0x1": {ID: "O_1", TX: "0x1", Status: sensors.StatusSucceed},
+		},
+	}
+
+	require.NoError(t, command(backend, []string{"watcher", "add", "-key", "receiver", "-address", "0xA1", "-chain", "137", "-confirmed", "12", "-pending-timeout", "10m"}))
+	require.Len(t, backend.watchers, 1)
+	require.Equal(t, "receiver", backend.watchers[0].Key)
+	require.Equal(t, int64(137), backend.watchers[0].ChainID)
+	require.Equal(t, int64(12), backend.watchers[0].Confirmed)
+	require.Equal(t, int64(600), backend.watchers[0].PendingTimeout)
+
+	require.Error(t, command(backend, []string{"watcher", "add", "-key", "receiver"}))
+
+	require.NoError(t, command(backend, []string{"watcher", "list"}))
+
+	require.NoError(t, command(backend, []string{"watcher", "remove", "receiver"}))
+	require.Equal(t, []string{"receiver"}, backend.deleted)
+
+	require.NoError(t, command(backend, []string{"order", "get", "0x1"}))
+	require.Equal(t, sensors.ErrOrderNotFound, command(backend, []string{"order", "get", "0x2"}))
+
+	require.NoError(t, command(backend, []string{"order", "recheck", "0x1"}))
+	require.Equal(t, []string{"0x1"}, backend.rechecks)
+
+	require.NoError(t, command(backend, []string{"order", "history", "0x1"}))
+	require.Error(t, command(backend, []string{"order", "cancel", "0x1"}))
+
+	require.NoError(t, command(backend, []string{"rewind", "100"}))
+	require.Equal(t, [][2]int64{{1, 100}}, backend.rewinds)
+
+	require.Error(t, command(backend, []string{"rewind", "latest"}))
+
+	require.NoError(t, command(backend, []string{"replay", "10", "20"}))
+	require.Equal(t, [][3]int64{{1, 10, 20}}, backend.replays)
+
+	require.Error(t, command(backend, []string{"replay", "10"}))
+	require.Error(t, command(backend, []string{"unknown"}))
+}
+
+func TestDBRewind(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sensorsctl")
+
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	db, err := xorm.NewEngine("sqlite3", filepath.Join(dir, "sensors.db"))
+
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	require.NoError(t, schema.Migrate(db, schema.Latest()))
+
+	backend := &dbBackend{db: db}
+
+	require.Error(t, backend.Rewind(1, 100))
+
+	_, err = db.InsertOne(&sensors.Cursor{ChainID: 1, Block: 200, Hash: "0x200", UpdateTime: time.Now()})
+
+	require.NoError(t, err)
+
+	require.NoError(t, backend.Rewind(1, 100))
+
+	cursor := new(sensors.Cursor)
+
+	ok, err := db.Where(`"chain_i_d" = ?`, 1).Get(cursor)
+
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int64(99), cursor.Block)
+	require.Equal(t, "", cursor.Hash)
+
+	// the running leader overwrite the cursor
+	_, err = db.InsertOne(&sensors.Lease{Name: "eth-sensors", Holder: "A", Expire: time.Now().Add(time.Minute).UnixNano() / int64(time.Millisecond)})
+
+	require.NoError(t, err)
+
+	require.Error(t, backend.Rewind(1, 50))
+}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"strings"
+
+	sensors "github.com/laplacenetwork/eth-sensors"
+)
+
+func (d *sensorsImpl) Order(tx string) (*sensors.Order, error) {
+	order, err := d.storage.Find(0, strings.ToLower(tx))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if order == nil {
+		return nil, sensors.ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+func (d *sensorsImpl) Recheck(tx string) (*sensors.Order, error) {
+	order, err := d.Order(tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	chain, err := d.chain(order.ChainID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !d.isLeader() {
+		return nil, sensors.ErrNotLeader
+	}
+
+	changed, err := d.recheck(chain, order)
+
+	for i := 0; err == sensors.ErrVersion && i < updateRetries; i++ {
+		d.WarnF("recheck order %s with tx %s version %d conflict, reload it", order.ID, order.TX, order.Version)
+
+		if order, err = d.Order(tx); err != nil {
+			return nil, err
+		}
+
+		changed, err = d.recheck(chain, order)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !changed {
+		return order, nil
+	}
+
+	if err := d.notify(order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// recheck re-evaluate the order receipt and save the order if its status or failure reason changed
+func (d *sensorsImpl) recheck(chain *chainDetector, order *sensors.Order) (bool, error) {
+	if order.Unconfirmed() {
+		return false, sensors.ErrOrderRunning
+	}
+
+	if order.Archived {
+		return false, sensors.ErrOrderArchived
+	}
+
+	reason, revert, timeout := order.FailureReason, order.RevertReason, order.TimeoutReason
+
+	ok, err := chain.orderRecipt(order)
+
+	if err != nil {
+		d.ErrorF("recheck order %s receipt err: %s", order.TX, err)
+		return false, err
+	}
+
+	status := sensors.StatusFailed
+
+	if ok {
+		status = chain.finality.succeed()
+
+		// keep the succeed status confirmed with the other mode
+		if succeeded(order.Status) {
+			status = order.Status
+		}
+	}
+
+	if status == order.Status && reason == order.FailureReason && revert == order.RevertReason && timeout == order.TimeoutReason {
+		return false, nil
+	}
+
+	d.InfoF("recheck order %s with tx %s status %s -> %s(%s)", order.ID, order.TX, order.Status, status, order.FailureReason)
+
+	var histories []*sensors.OrderHistory
+
+	// the failure reason may change without status transition
+	if status != order.Status {
+		history, err := order.Transit(status, -1, d.clock.Now(), "recheck")
+
+		if err != nil {
+			d.ErrorF("recheck order %s err: %s", order.TX, err)
+			return false, err
+		}
+
+		histories = append(histories, history)
+	}
+
+	order.ConfirmTime = d.clock.Now()
+
+	// persist before notify, the watchers never see the status not saved
+	if err := d.storage.Update(order, histories...); err != nil {
+		d.ErrorF("save order %s err: %s", order.TX, err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (d *sensorsImpl) History(tx string) ([]*sensors.OrderHistory, error) {
+	order, err := d.Order(tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return d.storage.History(order.ID)
+}
+
+func (d *sensorsImpl) Rewind(chainID int64, block int64) error {
+	chain, err := d.chain(chainID)
+
+	if err != nil {
+		return err
+	}
+
+	if !d.isLeader() {
+		return sensors.ErrNotLeader
+	}
+
+	d.InfoF("rewind chain %d to block %d", chainID, block)
+
+	return chain.rewind(block)
+}
+
+func (d *sensorsImpl) Replay(chainID int64, from int64, to int64) (int, error) {
+	if _, err := d.chain(chainID); err != nil {
+		return 0, err
+	}
+
+	if !d.isLeader() {
+		return 0, sensors.ErrNotLeader
+	}
+
+	orders, err := d.storage.Range(chainID, from, to)
+
+	if err != nil {
+		return 0, err
+	}
+
+	d.InfoF("replay chain %d orders(%d) notifications in block [%d,%d]", chainID, len(orders), from, to)
+
+	for i, order := range orders {
+		if err := d.notify(order); err != nil {
+			return i, err
+		}
+	}
+
+	return len(orders), nil
+}
+
+func (d *sensorsImpl) notify(order *sensors.Order) error {
+	watchers, err := d.getWatchers(order)
+
+	if err != nil {
+		d.ErrorF("notify tx %s err: %s", order.TX, err)
+		return err
+	}
+
+	for _, watcher := range watchers {
+		if err := d.notifier.Notify(watcher, order); err != nil {
+			d.ErrorF("notify tx %s to watcher %s err: %s", order.TX, watcher.Key, err)
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+	"time"
+
+	sensors "github.com/laplacenetwork/eth-sensors"
+)
+
+// nativeDecimals the decimals of the chain native coin
+const nativeDecimals = 18
+
+// fiatDecimals the max fraction digits of the fiat value
+const fiatDecimals = 6
+
+// amount attach the transferred asset and its decimal amount to the order, valued by the price oracle at block time,
+// the error getting the token metadata is returned to retry the block, the amount of the non erc20 contract is left empty
+func (chain *chainDetector) amount(order *sensors.Order, blockTime time.Time) error {
+	asset, amount := transfer(order)
+
+	if asset == nativeAsset {
+		order.Symbol = chain.symbol
+		order.Amount = formatUnits(amount, nativeDecimals)
+	} else {
+		token, err := chain.token(asset)
+
+		if _, ok := err.(*tokenError); ok {
+			chain.WarnF("get erc20 %s of tx %s err: %s", asset, order.TX, err)
+			order.Token = asset
+			return nil
+		}
+
+		if err != nil {
+			chain.ErrorF("get erc20 %s of tx %s err: %s", asset, order.TX, err)
+			return err
+		}
+
+		order.Token = asset
+
+		order.Symbol = token.Symbol
+		order.Amount = formatUnits(amount, token.Decimals)
+	}
+
+	if chain.oracle == nil || order.Symbol == "" {
+		return nil
+	}
+
+	price, ok, err := chain.oracle.Price(chain.id, order.Symbol, blockTime)
+
+	if err != nil {
+		chain.WarnF("get %s price of tx %s err: %s", order.Symbol, order.TX, err)
+		return nil
+	}
+
+	if !ok {
+		return nil
+	}
+
+	value, _ := new(big.Rat).SetString(order.Amount)
+
+	order.FiatValue = trimFraction(value.Mul(value, price).FloatString(fiatDecimals))
+	order.FiatCurrency = chain.oracle.Currency()
+
+	return nil
+}
+
+// formatUnits format the integer amount parsed by fixed with decimals, the text is built by big.Int instead of
+// a float conversion to keep the 18 decimals amounts exact, the trailing zeros of the fraction are trimmed
+func formatUnits(amount *big.Int, decimals int64) string {
+	if decimals <= 0 {
+		return amount.String()
+	}
+
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(decimals), nil)
+
+	integer, fraction := new(big.Int).QuoRem(new(big.Int).Abs(amount), unit, new(big.Int))
+
+	text := integer.String()
+
+	if fraction.Sign() != 0 {
+		digits := fraction.String()
+
+		text += "." + strings.TrimRight(strings.Repeat("0", int(decimals)-len(digits))+digits, "0")
+	}
+
+	if amount.Sign() < 0 {
+		text = "-" + text
+	}
+
+	return text
+}
+
+// trimFraction trim the trailing zeros of the decimal fraction
+func trimFraction(text string) string {
+	if !strings.Contains(text, ".") {
+		return text
+	}
+
+	return strings.TrimSuffix(strings.TrimRight(text, "0"), ".")
+}
@@ -0,0 +1,19 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatUnits(t *testing.T) {
+	require.Equal(t, "1.5", formatUnits(big.NewInt(150), 2))
+	require.Equal(t, "0.000001", formatUnits(big.NewInt(1), 6))
+	require.Equal(t, "12", formatUnits(big.NewInt(12000000), 6))
+	require.Equal(t, "-0.25", formatUnits(big.NewInt(-25), 2))
+	require.Equal(t, "7", formatUnits(big.NewInt(7), 0))
+
+	require.Equal(t, "12.5", trimFraction("12.500000"))
+	require.Equal(t, "12", trimFraction("12.000000"))
+}
@@ -0,0 +1,759 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dynamicgo/go-config-extend"
+
+	"github.com/dynamicgo/fixed"
+	config "github.com/dynamicgo/go-config"
+	"github.com/dynamicgo/slf4go"
+	"github.com/dynamicgo/xorm-decorator"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/laplacenetwork/eth-sensors/metrics"
+	"github.com/laplacenetwork/eth-sensors/rpcpool"
+	"github.com/openzknetwork/ethgo/rpc"
+)
+
+// updateRetries the order update retry times on version conflict
+const updateRetries = 3
+
+// chainDetector detect txs of one chain
+type chainDetector struct {
+	slf4go.Logger
+	*sensorsImpl
+	id      int64
+	label   string // chain id metrics label
+	symbol  string // the native coin symbol
+	source  sensors.BlockSource
+	cacher  sensors.OrderCacher
+	url     string        // json rpc url, the local rpc pool url if pool configured
+	client  *http.Client  // the json rpc client with the "rpc" section timeout
+	pool    *rpcpool.Pool // nil if pool not configured
+	ethnode *rpc.Client
+	state   syncState
+	policy  *policy
+	// confirm orders by the safe or finalized block tag if enabled
+	finality *finality
+	// parallel receipt fetching goroutines of the confirmed orders
+	receiptWorkers int
+	// the block source factory and config to create the closable source again after stopped
+	sourceF      sensors.BlockSourceF
+	sourceConfig config.Config
+	sourceLock   sync.Mutex
+	running      bool
+	// the cacher factory and config to drop the local cached orders after the replica lost the lease
+	cacherF      sensors.OrderCacherF
+	cacherConfig config.Config
+	cacherLock   sync.RWMutex
+	// held while handling one block, stopping waits for the block in flight
+	handling sync.Mutex
+	// the registered erc20 tokens by contract address
+	tokens     map[string]*sensors.ERC20
+	tokensLock sync.Mutex
+}
+
+func newChainDetector(impl *sensorsImpl, name string, chainConfig config.Config, globalConfig config.Config, plugin *sensors.Plugin) (*chainDetector, error) {
+	id := int64(chainConfig.Get("chainid").Int(1))
+
+	chain := &chainDetector{
+		Logger:         slf4go.Get("sensors-" + name),
+		sensorsImpl:    impl,
+		id:             id,
+		label:          strconv.FormatInt(id, 10),
+		symbol:         chainConfig.Get("symbol").String("ETH"),
+		receiptWorkers: chainConfig.Get("prefetch", "receipts").Int(8),
+		client:         &http.Client{Timeout: chainConfig.Get("rpc", "timeout").Duration(10 * time.Second)},
+		tokens:         make(map[string]*sensors.ERC20),
+	}
+
+	// per chain cacher config override the global one
+	cacherConfig, err := extend.SubConfig(globalConfig, "cacher")
+
+	if hasSection(chainConfig, "cacher") {
+		cacherConfig, err = extend.SubConfig(chainConfig, "cacher")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// per chain policies override the global ones
+	policyConfig := globalConfig
+
+	if hasSection(chainConfig, "policies") {
+		policyConfig = chainConfig
+	}
+
+	policy, err := newPolicy(policyConfig, cacherConfig)
+
+	if err != nil {
+		return nil, err
+	}
+
+	chain.policy = policy
+
+	finality, err := newFinality(chainConfig)
+
+	if err != nil {
+		return nil, err
+	}
+
+	chain.finality = finality
+
+	if chain.receiptWorkers < 1 {
+		chain.receiptWorkers = 1
+	}
+
+	if err := chain.createSource(chainConfig, plugin); err != nil {
+		return nil, err
+	}
+
+	cacher, err := plugin.OrderCacherCreator(cacherConfig)
+
+	if err != nil {
+		chain.close()
+		return nil, err
+	}
+
+	if shared, ok := cacher.(sensors.SharedOrderCacher); ok {
+		shared.Bind(id)
+	}
+
+	chain.cacher = cacher
+	chain.cacherF = plugin.OrderCacherCreator
+	chain.cacherConfig = cacherConfig
+
+	return chain, nil
+}
+
+func hasSection(config config.Config, path ...string) bool {
+	data := config.Get(path...).Bytes()
+
+	return len(data) > 0 && string(data) != "null"
+}
+
+func (chain *chainDetector) createSource(config config.Config, plugin *sensors.Plugin) error {
+
+	chain.url = config.Get("ethnode").String("http://localhost:8545")
+
+	// fetch blocks and receipts through the endpoint pool if configured
+	if hasSection(config, "rpc") {
+		poolConfig, err := extend.SubConfig(config, "rpc")
+
+		if err != nil {
+			return err
+		}
+
+		pool, err := rpcpool.New(chain.label, poolConfig)
+
+		if err != nil {
+			chain.ErrorF("create rpc pool err: %s", err)
+			return err
+		}
+
+		if err := pool.Start(); err != nil {
+			chain.ErrorF("start rpc pool err: %s", err)
+			return err
+		}
+
+		chain.pool = pool
+		chain.url = pool.URL()
+	}
+
+	chain.ethnode = rpc.NewClient(chain.url)
+
+	name := config.Get("source").String("")
+
+	if name == "" {
+		name = "indexer"
+
+		if hasSection(config, "websocket") {
+			name = "websocket"
+		}
+	}
+
+	sourceF, ok := plugin.BlockSourceCreators[name]
+
+	if !ok {
+		chain.close()
+		return fmt.Errorf("expect import block source %s implement", name)
+	}
+
+	source, err := sourceF(config, chain)
+
+	if err != nil {
+		chain.ErrorF("create block source %s err: %s", name, err)
+		chain.close()
+		return err
+	}
+
+	chain.source = source
+	chain.sourceF = sourceF
+	chain.sourceConfig = config
+
+	return nil
+}
+
+// close stop the rpc pool of the chain not started
+func (chain *chainDetector) close() {
+	if chain.pool != nil {
+		chain.pool.Close()
+	}
+}
+
+// run start the block source if not running, the stopped source is created again
+func (chain *chainDetector) run() error {
+	chain.sourceLock.Lock()
+	defer chain.sourceLock.Unlock()
+
+	if chain.running {
+		return nil
+	}
+
+	if chain.source == nil {
+		source, err := chain.sourceF(chain.sourceConfig, chain)
+
+		if err != nil {
+			chain.ErrorF("create block source err: %s", err)
+			return err
+		}
+
+		chain.source = source
+	}
+
+	chain.running = true
+
+	go func(source sensors.BlockSource) {
+		if err := source.Run(); err != nil {
+			chain.ErrorF("block source stopped err: %s", err)
+		}
+	}(chain.source)
+
+	return nil
+}
+
+// stop close the block source and drop the local cached orders after the replica lost the lease,
+// the source not closable keeps running and its blocks are rejected until the replica leads again
+func (chain *chainDetector) stop() {
+	chain.sourceLock.Lock()
+
+	if closable, ok := chain.source.(sensors.ClosableBlockSource); ok && chain.running {
+		if err := closable.Close(); err != nil {
+			chain.WarnF("close block source err: %s", err)
+		} else {
+			chain.source = nil
+			chain.running = false
+		}
+	}
+
+	chain.sourceLock.Unlock()
+
+	// the block in flight is fenced before notifying and saving, wait for it to recache its orders
+	chain.handling.Lock()
+	defer chain.handling.Unlock()
+
+	// the shared cacher is kept for the next leader
+	if _, ok := chain.cacher.(sensors.SharedOrderCacher); ok {
+		return
+	}
+
+	cacher, err := chain.cacherF(chain.cacherConfig)
+
+	if err != nil {
+		chain.ErrorF("create cacher err: %s", err)
+		return
+	}
+
+	chain.cacherLock.Lock()
+	chain.cacher = cacher
+	chain.cacherLock.Unlock()
+}
+
+// rewind feed blocks from block again by the running source
+func (chain *chainDetector) rewind(block int64) error {
+	chain.sourceLock.Lock()
+	defer chain.sourceLock.Unlock()
+
+	if !chain.running {
+		return sensors.ErrNotLeader
+	}
+
+	return chain.source.Rewind(block)
+}
+
+// fence reject notifying and saving after the replica lost the lease
+func (chain *chainDetector) fence() error {
+	if !chain.isLeader() {
+		return sensors.ErrNotLeader
+	}
+
+	return nil
+}
+
+func (chain *chainDetector) watchHead(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		start := time.Now()
+
+		head, err := chain.ethnode.BlockNumber()
+
+		metrics.ObserveRPC("core", chain.label, "eth_blockNumber", start, err)
+
+		if err != nil {
+			chain.WarnF("get chain head err: %s", err)
+			continue
+		}
+
+		lag := chain.state.chainHead(head)
+
+		metrics.ChainHead.WithLabelValues(chain.label).Set(float64(head))
+		metrics.HeadLag.WithLabelValues(chain.label).Set(float64(lag))
+	}
+}
+
+// ID implement sensors.SourceChain
+func (chain *chainDetector) ID() int64 {
+	return chain.id
+}
+
+// Ethnode implement sensors.SourceChain
+func (chain *chainDetector) Ethnode() string {
+	return chain.url
+}
+
+// Contracts implement sensors.SourceChain, get the watched erc20 contract addresses of the chain
+func (chain *chainDetector) Contracts() ([]string, error) {
+	var watchers []*sensors.Watcher
+
+	if err := chain.db.Where(`"chain_i_d" = ? and "e_r_c20" = ?`, chain.id, true).Find(&watchers); err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, 0, len(watchers))
+
+	for _, watcher := range watchers {
+		addresses = append(addresses, watcher.Address)
+	}
+
+	return addresses, nil
+}
+
+// Cursor implement sensors.SourceChain, get the last processed block of the chain
+func (chain *chainDetector) Cursor() (int64, string, error) {
+	cursor := new(sensors.Cursor)
+
+	ok, err := chain.db.Where(`"chain_i_d" = ?`, chain.id).Get(cursor)
+
+	if err != nil {
+		return -1, "", err
+	}
+
+	if !ok {
+		return -1, "", nil
+	}
+
+	return cursor.Block, cursor.Hash, nil
+}
+
+// saveCursor persist the processed block as the chain cursor
+func (chain *chainDetector) saveCursor(block int64, hash string) error {
+	cursor := &sensors.Cursor{
+		ChainID:    chain.id,
+		Block:      block,
+		Hash:       hash,
+		UpdateTime: chain.clock.Now(),
+	}
+
+	start := time.Now()
+
+	affected, err := chain.db.Where(`"chain_i_d" = ?`, chain.id).Cols("block", "hash", "update_time").Update(cursor)
+
+	if err == nil && affected == 0 {
+		// the unchanged row may be reported as not affected
+		if _, err = chain.db.InsertOne(cursor); decorator.DuplicateKey(chain.db, err) {
+			err = nil
+		}
+	}
+
+	metrics.ObserveDB("core", "save_cursor", start, err)
+
+	return err
+}
+
+// Handle implement sensors.SourceChain
+func (chain *chainDetector) Handle(block *rpc.Block) (err error) {
+	chain.handling.Lock()
+	defer chain.handling.Unlock()
+
+	if !chain.isLeader() {
+		return sensors.ErrNotLeader
+	}
+
+	start := time.Now()
+
+	defer func() {
+		metrics.BlocksProcessed.WithLabelValues("core", chain.label, metrics.Result(err)).Inc()
+		metrics.BlockDuration.WithLabelValues("core", chain.label).Observe(time.Since(start).Seconds())
+	}()
+
+	blockNumber, _ := strconv.ParseUint(strings.TrimPrefix(block.Number, "0x"), 16, 64)
+
+	timestamp, _ := strconv.ParseInt(strings.TrimPrefix(block.Timestamp, "0x"), 16, 64)
+
+	blockTime := time.Unix(timestamp, 0)
+
+	for _, tx := range block.Transactions {
+		// chain.DebugF("handle tx(%s) ", tx.Hash)
+
+		err := chain.TX(tx, int64(blockNumber), blockTime)
+
+		if err != nil {
+			chain.ErrorF("handle tx(%s) err %s", tx.Hash, err)
+			return err
+		}
+
+		// chain.DebugF("handle tx(%s) -- success", tx.Hash)
+	}
+
+	chain.DebugF("handle block(%s)", block.Hash)
+
+	if err := chain.Block(block, int64(blockNumber), blockTime); err != nil {
+		chain.ErrorF("handle block(%s) err %s", block.Hash, err)
+		return err
+	}
+
+	chain.DebugF("handle block(%s) -- success", block.Hash)
+
+	chain.settle(blockTime)
+
+	if err := chain.saveCursor(int64(blockNumber), block.Hash); err != nil {
+		chain.ErrorF("save block(%s) cursor err %s", block.Hash, err)
+		return err
+	}
+
+	lag := chain.state.processed(int64(blockNumber), block.Hash, blockTime, chain.clock.Now())
+
+	metrics.ProcessedBlock.WithLabelValues(chain.label).Set(float64(blockNumber))
+	metrics.HeadLag.WithLabelValues(chain.label).Set(float64(lag))
+	metrics.CachedOrders.WithLabelValues("core", chain.label).Set(float64(chain.cacher.Size()))
+
+	return nil
+}
+
+func (chain *chainDetector) TX(tx *rpc.Transaction, blockNumber int64, blockTime time.Time) error {
+
+	order := &sensors.Order{
+		ID:           chain.ids.Generate("O_"),
+		ChainID:      chain.id,
+		TX:           tx.Hash,
+		PendingBlock: blockNumber,
+		CommitBlock:  blockNumber,
+		ConfirmBlock: -1,
+		Status:       sensors.StatusCreated,
+		PendingTime:  blockTime,
+		CreateTime:   chain.clock.Now(),
+		CommitTime:   blockTime,
+		From:         tx.From,
+		To:           tx.To,
+		Value:        tx.Value,
+		GasLimits:    tx.Gas,
+		GasPrice:     tx.GasPrice,
+		Code:         tx.Input,
+		Nonce:        tx.Nonce,
+	}
+
+	chain.DebugF("try get tx %s watcher", order.TX)
+
+	watchers, err := chain.getWatchers(order)
+
+	if err != nil {
+		return err
+	}
+
+	if len(watchers) == 0 {
+		// chain.DebugF("no watcher for tx %s", tx.Hash)
+		return nil
+	}
+
+	chain.DebugF("find watchers(%d) for tx %s", len(watchers), tx.Hash)
+
+	// the order saved before rewind or by the former leader is already cached or done
+	saved, err := chain.storage.Find(chain.id, tx.Hash)
+
+	if err != nil {
+		return err
+	}
+
+	if saved != nil {
+		chain.DebugF("skip saved tx %s order %s", tx.Hash, saved.ID)
+		return nil
+	}
+
+	gas, err := fixed.FromHex(tx.Gas, 0)
+
+	if err != nil {
+		chain.ErrorF("parse tx %s gas %s err: %s", tx.Hash, tx.Gas, err)
+		return nil
+	}
+
+	gasPrice, err := fixed.FromHex(tx.GasPrice, 0)
+
+	if err != nil {
+		chain.ErrorF("parse tx %s gas price %s err: %s", tx.Hash, tx.GasPrice, err)
+		return nil
+	}
+
+	gasLimits := new(big.Int).Quo(gas.ValueBigInteger(), gasPrice.ValueBigInteger())
+
+	order.GasLimits = fixed.NewWithBigint(gasLimits, 0).HexValue()
+
+	chain.policy.apply(order, watchers)
+
+	if err := chain.amount(order, blockTime); err != nil {
+		return err
+	}
+
+	history, err := order.Transit(chain.finality.committed(), blockNumber, blockTime, "")
+
+	if err != nil {
+		return err
+	}
+
+	chain.DebugF("notify watchers(%d) for tx %s, confirm depth %d", len(watchers), tx.Hash, order.ConfirmDepth)
+
+	if err := chain.fence(); err != nil {
+		return err
+	}
+
+	for _, watcher := range watchers {
+		chain.DebugF("notify watcher %s for tx %s", watcher.Address, tx.Hash)
+		if err := chain.notifier.Notify(watcher, order); err != nil {
+			chain.ErrorF("notify tx %s order to watcher %s err: %s", tx.Hash, watcher.Key, err)
+			return err
+		}
+	}
+
+	if err := chain.fence(); err != nil {
+		return err
+	}
+
+	if err := chain.storage.Save(order, history); err != nil {
+
+		chain.ErrorF("save tx %s order err: %s", tx.Hash, err)
+		return err
+	}
+
+	chain.cacher.Pend(order)
+
+	return nil
+}
+
+// receipts get the orders receipt status in parallel
+func (chain *chainDetector) receipts(orders []*sensors.Order) ([]bool, error) {
+	succeed := make([]bool, len(orders))
+	errs := make([]error, len(orders))
+
+	workers := make(chan struct{}, chain.receiptWorkers)
+
+	var wg sync.WaitGroup
+
+	for i, order := range orders {
+		wg.Add(1)
+
+		workers <- struct{}{}
+
+		go func(i int, order *sensors.Order) {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			succeed[i], errs[i] = chain.orderRecipt(order)
+		}(i, order)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return succeed, nil
+}
+
+// recache restore the orders taken by Confirm, statuses are the timeout orders status before timeout
+// recache rollback the unsaved transitions of orders taken by the cacher and cache them again,
+// histories is nil if the orders are not transited
+func (chain *chainDetector) recache(orders []*sensors.Order, histories []*sensors.OrderHistory) {
+	for i, order := range orders {
+		if histories != nil {
+			order.Rollback(histories[i])
+		}
+
+		order.TimeoutReason = ""
+		order.FailureReason = ""
+		order.RevertReason = ""
+		order.ConfirmBlock = -1
+	}
+
+	chain.cacher.Cache(orders)
+}
+
+// transit move the orders taken by the cacher to the confirmed status, the orders with illegal transition are failed,
+// or cached again if they can not fail
+func (chain *chainDetector) transit(orders []*sensors.Order, status func(i int) sensors.Status, blockNumber int64, blockTime time.Time) ([]*sensors.Order, []*sensors.OrderHistory) {
+	var transited []*sensors.Order
+	var histories []*sensors.OrderHistory
+
+	for i, order := range orders {
+		history, err := order.Transit(status(i), blockNumber, blockTime, failure(order))
+
+		if err != nil {
+			chain.ErrorF("fail order %s with tx %s err: %s", order.ID, order.TX, err)
+
+			order.FailureReason = sensors.FailureIllegal
+
+			history, err = order.Transit(sensors.StatusFailed, blockNumber, blockTime, err.Error())
+		}
+
+		if err != nil {
+			chain.ErrorF("keep order %s with tx %s err: %s", order.ID, order.TX, err)
+
+			order.FailureReason = ""
+
+			chain.cacher.Pend(order)
+			continue
+		}
+
+		order.ConfirmBlock = blockNumber
+		order.ConfirmTime = blockTime
+
+		transited = append(transited, order)
+		histories = append(histories, history)
+	}
+
+	return transited, histories
+}
+
+// update save the transited order, the version conflicted order is reloaded and re-evaluated
+func (chain *chainDetector) update(order *sensors.Order, history *sensors.OrderHistory) error {
+	err := chain.storage.Update(order, history)
+
+	for i := 0; err == sensors.ErrVersion && i < updateRetries; i++ {
+		chain.WarnF("update order %s with tx %s version %d conflict, reload it", order.ID, order.TX, order.Version)
+
+		var latest *sensors.Order
+
+		latest, err = chain.storage.Find(order.ChainID, order.TX)
+
+		if err != nil {
+			return err
+		}
+
+		// completed by other sensor replica
+		if latest == nil || !latest.Unconfirmed() {
+			return nil
+		}
+
+		next, transitErr := latest.Transit(history.To, history.Block, history.Time, history.Reason)
+
+		if transitErr != nil {
+			chain.WarnF("re-evaluate order %s with tx %s err: %s", latest.ID, latest.TX, transitErr)
+			chain.cacher.Pend(latest)
+			return nil
+		}
+
+		latest.ConfirmBlock = order.ConfirmBlock
+		latest.ConfirmTime = order.ConfirmTime
+		latest.TimeoutReason = order.TimeoutReason
+		latest.FailureReason = order.FailureReason
+		latest.RevertReason = order.RevertReason
+
+		err = chain.storage.Update(latest, next)
+	}
+
+	return err
+}
+
+// failure the transition reason of failed order
+func failure(order *sensors.Order) string {
+	if order.TimeoutReason != "" {
+		return string(order.FailureReason) + "/" + string(order.TimeoutReason)
+	}
+
+	return string(order.FailureReason)
+}
+
+func (chain *chainDetector) Block(block *rpc.Block, blockNumber int64, blockTime time.Time) error {
+
+	timeout, confirmed := chain.cacher.Confirm(blockNumber, blockTime)
+
+	for _, order := range timeout {
+		chain.InfoF("timeout(%s) order %s with tx %s block %d", order.TimeoutReason, order.ID, order.TX, blockNumber)
+
+		order.FailureReason = sensors.FailureTimeout
+	}
+
+	succeed, err := chain.receipts(confirmed)
+
+	if err != nil {
+		chain.recache(append(timeout, confirmed...), nil)
+		return err
+	}
+
+	timeout, histories := chain.transit(timeout, func(int) sensors.Status {
+		return sensors.StatusFailed
+	}, blockNumber, blockTime)
+
+	confirmed, confirmedHistories := chain.transit(confirmed, func(i int) sensors.Status {
+		if succeed[i] {
+			return sensors.StatusSucceed
+		}
+
+		return sensors.StatusFailed
+	}, blockNumber, blockTime)
+
+	for _, order := range confirmed {
+		chain.InfoF("confirmed order %s with tx %s block %d status %s", order.ID, order.TX, blockNumber, order.Status)
+	}
+
+	orders := append(timeout, confirmed...)
+	histories = append(histories, confirmedHistories...)
+
+	if err := chain.fence(); err != nil {
+		chain.recache(orders, histories)
+		return err
+	}
+
+	for _, order := range orders {
+		if err := chain.notify(order); err != nil {
+			chain.recache(orders, histories)
+			return err
+		}
+	}
+
+	if err := chain.fence(); err != nil {
+		chain.recache(orders, histories)
+		return err
+	}
+
+	for i, order := range orders {
+		if err := chain.update(order, histories[i]); err != nil {
+			chain.ErrorF("save order %s err: %s", order.TX, err)
+			// the orders before are saved already
+			chain.recache(orders[i:], histories[i:])
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dynamicgo/slf4go"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/laplacenetwork/eth-sensors/cacher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransitIllegal(t *testing.T) {
+	chain := &chainDetector{
+		Logger: slf4go.Get("chain-test"),
+		cacher: cacher.NewCacher(1, 10, 0, 0),
+	}
+
+	orders := []*sensors.Order{
+		{ID: "O_1", TX: "0x1", Status: sensors.StatusRunning, ConfirmBlock: -1},
+		{ID: "O_2", TX: "0x2", Status: sensors.StatusPending, ConfirmBlock: -1},
+		{ID: "O_3", TX: "0x3", Status: sensors.StatusCanceled, ConfirmBlock: -1},
+	}
+
+	blockTime := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	transited, histories := chain.transit(orders, func(int) sensors.Status {
+		return sensors.StatusSucceed
+	}, 20, blockTime)
+
+	require.Len(t, transited, 2)
+	require.Len(t, histories, 2)
+
+	require.Equal(t, sensors.StatusSucceed, transited[0].Status)
+	require.Equal(t, int64(20), transited[0].ConfirmBlock)
+
+	// the pending order can not succeed, failed instead of dropped
+	require.Equal(t, "O_2", transited[1].ID)
+	require.Equal(t, sensors.StatusFailed, transited[1].Status)
+	require.Equal(t, sensors.FailureIllegal, transited[1].FailureReason)
+	require.Equal(t, sensors.StatusPending, histories[1].From)
+	require.Contains(t, histories[1].Reason, "PENDING -> SUCCEED")
+
+	// the terminal order can not fail either, kept cached
+	require.Equal(t, 1, chain.cacher.Size())
+}
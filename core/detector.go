@@ -1,39 +1,36 @@
 package core
 
 import (
-	"math/big"
-	"strconv"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/dynamicgo/go-config-extend"
 
-	"github.com/dynamicgo/fixed"
-
 	"github.com/dynamicgo/xorm-decorator"
 	"github.com/openzknetwork/ethgo/erc20"
-	"github.com/openzknetwork/ethgo/rpc"
-	"github.com/openzknetwork/indexer"
 
-	"github.com/bwmarrin/snowflake"
 	config "github.com/dynamicgo/go-config"
 	"github.com/dynamicgo/orm"
 	"github.com/dynamicgo/slf4go"
 	"github.com/go-xorm/xorm"
 	xormrediscache "github.com/go-xorm/xorm-redis-cache"
 	sensors "github.com/laplacenetwork/eth-sensors"
-	ethfetcher "github.com/openzknetwork/indexer/eth"
+	schema "github.com/laplacenetwork/eth-sensors/db"
+	"github.com/laplacenetwork/eth-sensors/metrics"
 )
 
 type sensorsImpl struct {
 	slf4go.Logger
-	db       *xorm.Engine
-	snode    *snowflake.Node
-	indexer  indexer.Indexer
-	cacher   sensors.OrderCacher
-	storage  sensors.OrderStorage
-	notifier sensors.Notifier
-	ethnode  *rpc.Client
+	db          *xorm.Engine
+	ids         sensors.IDGenerator
+	clock       sensors.Clock
+	storage     sensors.OrderStorage
+	notifier    sensors.Notifier
+	notifyState notifyState
+	chains      map[int64]*chainDetector
+	leader      *leader             // nil if the replica runs alone
+	oracle      sensors.PriceOracle // nil if the orders are not valued
 }
 
 // New create the sensors engine service
@@ -43,20 +40,35 @@ func New(config config.Config, plugin *sensors.Plugin) (sensors.Sensor, error) {
 		Logger: slf4go.Get("sensors"),
 	}
 
-	snode, err := snowflake.NewNode(int64(config.Get("snode").Int(4)))
+	impl.ids = plugin.IDGenerator
 
-	if err != nil {
-		impl.ErrorF("create snode err: 5s", err)
-		return nil, err
+	if impl.ids == nil {
+		ids, err := sensors.NewSnowflake(int64(config.Get("snode").Int(4)))
+
+		if err != nil {
+			impl.ErrorF("create snode err: %s", err)
+			return nil, err
+		}
+
+		impl.ids = ids
 	}
 
-	impl.snode = snode
+	impl.clock = plugin.Clock
 
-	if err := impl.createDB(config); err != nil {
-		return nil, err
+	if impl.clock == nil {
+		impl.clock = sensors.SystemClock
+	}
+
+	impl.db = plugin.DB
+
+	if impl.db == nil {
+		if err := impl.createDB(config); err != nil {
+			return nil, err
+		}
 	}
 
-	if err := impl.createIndexer(config); err != nil {
+	// refuse to run on the outdated tables, apply the migrations first
+	if err := schema.Check(impl.db); err != nil {
 		return nil, err
 	}
 
@@ -66,7 +78,7 @@ func New(config config.Config, plugin *sensors.Plugin) (sensors.Sensor, error) {
 		return nil, err
 	}
 
-	storage, err := plugin.OrderStorageCreator(storageConfig)
+	storage, err := plugin.OrderStorageCreator(storageConfig, impl.clock)
 
 	if err != nil {
 		return nil, err
@@ -74,98 +86,211 @@ func New(config config.Config, plugin *sensors.Plugin) (sensors.Sensor, error) {
 
 	impl.storage = storage
 
-	cacherConfig, err := extend.SubConfig(config, "cacher")
+	notifierConfig, err := extend.SubConfig(config, "notifier")
 
 	if err != nil {
 		return nil, err
 	}
 
-	cacher, err := plugin.OrderCacherCreator(cacherConfig)
+	notifier, err := plugin.NotifierCreator(notifierConfig)
 
 	if err != nil {
 		return nil, err
 	}
 
-	impl.cacher = cacher
+	impl.notifier = &trackedNotifier{
+		Notifier: metrics.Notifier(notifierConfig.Get("driver").String("default"), notifier),
+		state:    &impl.notifyState,
+	}
 
-	notifierConfig, err := extend.SubConfig(config, "notifier")
+	if plugin.PriceOracleCreator != nil && hasSection(config, "oracle") {
+		oracleConfig, err := extend.SubConfig(config, "oracle")
 
-	if err != nil {
-		return nil, err
+		if err != nil {
+			return nil, err
+		}
+
+		impl.oracle, err = plugin.PriceOracleCreator(oracleConfig)
+
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	notifier, err := plugin.NotifierCreator(notifierConfig)
+	chainConfigs, err := loadChainConfigs(config)
 
 	if err != nil {
 		return nil, err
 	}
 
-	impl.notifier = notifier
+	impl.chains = make(map[int64]*chainDetector)
 
-	orders, err := impl.storage.Unconfirmed()
+	for name, chainConfig := range chainConfigs {
+		chain, err := newChainDetector(impl, name, chainConfig, config, plugin)
 
-	if err != nil {
-		return nil, err
+		if err != nil {
+			impl.close()
+			return nil, err
+		}
+
+		if _, ok := impl.chains[chain.id]; ok {
+			chain.close()
+			impl.close()
+			return nil, fmt.Errorf("duplicate chain %d config %s", chain.id, name)
+		}
+
+		impl.chains[chain.id] = chain
 	}
 
-	impl.DebugF("load unconfirmed orders %d", len(orders))
+	headInterval := config.Get("head", "interval").Duration(time.Second * 10)
+
+	for _, chain := range impl.chains {
+		go chain.watchHead(headInterval)
+	}
 
-	impl.cacher.Cache(orders)
+	// the replicas sharing the database elect the leader running the block sources
+	if !hasSection(config, "leader") {
+		if err := impl.takeover(); err != nil {
+			impl.close()
+			return nil, err
+		}
+	} else {
+		impl.leader = newLeader(config, impl.db, impl.clock)
 
-	go impl.indexer.Run()
+		impl.leader.campaign(impl.takeover, impl.resign)
+
+		go impl.leader.run(impl.takeover, impl.resign)
+	}
+
+	if retention := newRetention(impl, config); retention != nil {
+		go retention.run()
+	}
 
 	return impl, nil
 }
 
-func (d *sensorsImpl) createIndexer(config config.Config) error {
+// takeover load the unconfirmed orders left by the former leader and start the block sources
+func (d *sensorsImpl) takeover() error {
+	if err := d.loadUnconfirmed(); err != nil {
+		return err
+	}
+
+	for _, chain := range d.chains {
+		if err := chain.run(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resign stop the block sources and drop the local cached orders after the replica lost the lease
+func (d *sensorsImpl) resign() {
+	for _, chain := range d.chains {
+		chain.stop()
+	}
+}
+
+// Close implement sensors.ClosableSensor
+func (d *sensorsImpl) Close() error {
+	if d.leader == nil {
+		d.resign()
+		return nil
+	}
+
+	return d.leader.release(d.resign)
+}
+
+// close stop the rpc pools of the chains created by the failed sensor creation
+func (d *sensorsImpl) close() {
+	for _, chain := range d.chains {
+		chain.close()
+	}
+}
+
+// isLeader check if the replica runs the block sources, the replica running alone is always the leader
+func (d *sensorsImpl) isLeader() bool {
+	return d.leader == nil || d.leader.isLeader()
+}
+
+// loadUnconfirmed load the unconfirmed orders into chain cachers, shared cachers already loaded are skipped
+func (d *sensorsImpl) loadUnconfirmed() error {
+	reload := make(map[int64]*chainDetector)
 
-	ethnode := config.Get("ethnode").String("http://localhost:8545")
+	for id, chain := range d.chains {
+		if shared, ok := chain.cacher.(sensors.SharedOrderCacher); ok && shared.Loaded() {
+			d.DebugF("chain %d cacher already loaded, skip reloading", id)
+			continue
+		}
+
+		reload[id] = chain
+	}
 
-	fetcher := ethfetcher.New(ethnode, ethfetcher.HandleFunc(d.fetchHandler))
+	if len(reload) == 0 {
+		return nil
+	}
 
-	idx, err := indexer.New(config, fetcher)
+	orders, err := d.storage.Unconfirmed()
 
 	if err != nil {
-		d.ErrorF("create indexer err: %s", err)
 		return err
 	}
 
-	d.indexer = idx
-	d.ethnode = rpc.NewClient(ethnode)
+	d.DebugF("load unconfirmed orders %d", len(orders))
+
+	chainOrders := make(map[int64][]*sensors.Order)
+
+	for _, order := range orders {
+		chainOrders[order.ChainID] = append(chainOrders[order.ChainID], order)
+	}
+
+	for chainID, chain := range reload {
+		// cache even if no order, mark the shared cacher loaded
+		chain.cacher.Cache(chainOrders[chainID])
+
+		delete(chainOrders, chainID)
+	}
+
+	for chainID, orders := range chainOrders {
+		if _, ok := d.chains[chainID]; !ok {
+			d.WarnF("skip unconfirmed orders(%d) of unknown chain %d", len(orders), chainID)
+		}
+	}
 
 	return nil
 }
 
-func (d *sensorsImpl) fetchHandler(block *rpc.Block) error {
-	blockNumber, _ := strconv.ParseUint(strings.TrimPrefix(block.Number, "0x"), 16, 64)
+// loadChainConfigs load the config of every chain, use the root config as the only chain config if chains not configured
+func loadChainConfigs(conf config.Config) (map[string]config.Config, error) {
+	chains := make(map[string]interface{})
 
-	timestamp, _ := strconv.ParseInt(strings.TrimPrefix(block.Timestamp, "0x"), 16, 64)
-
-	blockTime := time.Unix(timestamp, 0)
+	if err := conf.Get("chains").Scan(&chains); err != nil || len(chains) == 0 {
+		return map[string]config.Config{"default": conf}, nil
+	}
 
-	for _, tx := range block.Transactions {
-		// d.DebugF("handle tx(%s) ", tx.Hash)
+	configs := make(map[string]config.Config)
 
-		err := d.TX(tx, int64(blockNumber), blockTime)
+	for name := range chains {
+		chainConfig, err := extend.SubConfig(conf, "chains", name)
 
 		if err != nil {
-			d.ErrorF("handle tx(%s) err %s", tx.Hash, err)
-			return err
+			return nil, err
 		}
 
-		// d.DebugF("handle tx(%s) -- success", tx.Hash)
+		configs[name] = chainConfig
 	}
 
-	d.DebugF("handle block(%s)", block.Hash)
+	return configs, nil
+}
 
-	if err := d.Block(block, int64(blockNumber), blockTime); err != nil {
-		d.ErrorF("handle block(%s) err %s", block.Hash, err)
-		return err
-	}
+func (d *sensorsImpl) chain(chainID int64) (*chainDetector, error) {
+	chain, ok := d.chains[chainID]
 
-	d.DebugF("handle block(%s) -- success", block.Hash)
+	if !ok {
+		return nil, sensors.ErrChainNotFound
+	}
 
-	return nil
+	return chain, nil
 }
 
 func (d *sensorsImpl) getWatchers(order *sensors.Order) ([]*sensors.Watcher, error) {
@@ -184,7 +309,11 @@ func (d *sensorsImpl) getWatchers(order *sensors.Order) ([]*sensors.Watcher, err
 
 	d.DebugF("find watcher for %s or %s", order.From, to)
 
-	err = d.db.Where(`("address" = ? or "address" = ?) and "e_r_c20" = ?`, order.From, to, false).Find(&watchers)
+	start := time.Now()
+
+	err = d.db.Where(`("address" = ? or "address" = ?) and "chain_i_d" = ? and "e_r_c20" = ?`, order.From, to, order.ChainID, false).Find(&watchers)
+
+	metrics.ObserveDB("core", "get_watchers", start, err)
 
 	if err != nil {
 		d.DebugF("find watcher for %s or %s err: %s", order.From, to, err)
@@ -198,7 +327,7 @@ func (d *sensorsImpl) erc20Watcher(order *sensors.Order) (string, error) {
 
 	watcher := new(sensors.Watcher)
 
-	ok, err := d.db.Where(`"address" = ? and "e_r_c20" = ?`, order.To, true).Get(watcher)
+	ok, err := d.db.Where(`"address" = ? and "chain_i_d" = ? and "e_r_c20" = ?`, order.To, order.ChainID, true).Get(watcher)
 
 	if err != nil {
 		return "", err
@@ -231,173 +360,6 @@ func (d *sensorsImpl) erc20Watcher(order *sensors.Order) (string, error) {
 	return to, nil
 }
 
-func (d *sensorsImpl) TX(tx *rpc.Transaction, blockNumber int64, blockTime time.Time) error {
-
-	order := &sensors.Order{
-		ID:           "O_" + d.snode.Generate().String(),
-		TX:           tx.Hash,
-		PendingBlock: blockNumber,
-		CommitBlock:  blockNumber,
-		ConfirmBlock: -1,
-		Status:       sensors.StatusRunning,
-		PendingTime:  blockTime,
-		CreateTime:   blockTime,
-		CommitTime:   blockTime,
-		From:         tx.From,
-		To:           tx.To,
-		Value:        tx.Value,
-		GasLimits:    tx.Gas,
-		GasPrice:     tx.GasPrice,
-		Code:         tx.Input,
-	}
-
-	d.DebugF("try get tx %s watcher", order.TX)
-
-	watchers, err := d.getWatchers(order)
-
-	if err != nil {
-		return err
-	}
-
-	if len(watchers) == 0 {
-		// d.DebugF("no watcher for tx %s", tx.Hash)
-		return nil
-	}
-
-	d.DebugF("find watchers(%d) for tx %s", len(watchers), tx.Hash)
-
-	gas, err := fixed.FromHex(tx.Gas, 0)
-
-	if err != nil {
-		d.ErrorF("parse tx %s gas %s err: %s", tx.Hash, tx.Gas, err)
-		return nil
-	}
-
-	gasPrice, err := fixed.FromHex(tx.GasPrice, 0)
-
-	if err != nil {
-		d.ErrorF("parse tx %s gas price %s err: %s", tx.Hash, tx.GasPrice, err)
-		return nil
-	}
-
-	gasLimits := new(big.Int).Quo(gas.ValueBigInteger(), gasPrice.ValueBigInteger())
-
-	order.GasLimits = fixed.NewWithBigint(gasLimits, 0).HexValue()
-
-	d.DebugF("notify watchers(%d) for tx %s", len(watchers), tx.Hash)
-
-	for _, watcher := range watchers {
-		d.DebugF("notify watcher %s for tx %s", watcher.Address, tx.Hash)
-		if err := d.notifier.Notify(watcher, order); err != nil {
-			d.ErrorF("notify tx %s order to watcher %s err: %s", tx.Hash, watcher.Key, err)
-			return err
-		}
-	}
-
-	if err := d.storage.Save(order); err != nil {
-
-		d.ErrorF("save tx %s order err: %s", tx.Hash, err)
-		return err
-	}
-
-	d.cacher.Pend(order)
-
-	return nil
-}
-
-func (d *sensorsImpl) orderRecipt(tx string) (bool, error) {
-	recipt, err := d.ethnode.GetTransactionReceipt(tx)
-
-	if err != nil {
-		return false, err
-	}
-
-	if recipt.Status == "0x0" {
-		return false, nil
-	}
-
-	return true, nil
-}
-
-func (d *sensorsImpl) recache(timeout, confirmed []*sensors.Order) {
-	for _, order := range timeout {
-
-		order.Status = sensors.StatusPending
-	}
-
-	for _, order := range confirmed {
-
-		order.Status = sensors.StatusRunning
-	}
-
-	d.cacher.Cache(append(timeout, confirmed...))
-}
-
-func (d *sensorsImpl) Block(block *rpc.Block, blockNumber int64, blockTime time.Time) error {
-
-	timeout, confirmed := d.cacher.Confirm(blockNumber, blockTime)
-
-	for _, order := range timeout {
-		d.InfoF("timeout order %s with tx %s block %d", order.ID, order.TX, blockNumber)
-
-		order.Status = sensors.StatusFailed
-
-		order.ConfirmBlock = blockNumber
-		order.ConfirmTime = blockTime
-	}
-
-	for _, order := range confirmed {
-		d.InfoF("confirmed order %s with tx %s block %d", order.ID, order.TX, blockNumber)
-		ok, err := d.orderRecipt(order.TX)
-
-		if err != nil {
-			d.recache(timeout, confirmed)
-			return err
-		}
-
-		if ok {
-			order.Status = sensors.StatusSucceed
-		} else {
-			order.Status = sensors.StatusFailed
-		}
-
-		order.ConfirmBlock = blockNumber
-		order.ConfirmTime = blockTime
-	}
-
-	orders := append(timeout, confirmed...)
-
-	for _, order := range orders {
-		watchers, err := d.getWatchers(order)
-
-		if err != nil {
-			d.ErrorF("notify tx %s completed err: %s", order.TX, err)
-			d.recache(timeout, confirmed)
-			return err
-		}
-
-		d.DebugF("find watchers(%d) for tx %s to notify", len(watchers), order.TX)
-
-		for _, watcher := range watchers {
-			if err := d.notifier.Notify(watcher, order); err != nil {
-				d.ErrorF("notify tx %s completed err: %s", order.TX, err)
-				d.recache(timeout, confirmed)
-				return err
-			}
-		}
-	}
-
-	for _, order := range orders {
-		if err := d.storage.Update(order); err != nil {
-			d.ErrorF("save order %s err: %s", order.TX, err)
-			d.recache(timeout, confirmed)
-			return err
-		}
-	}
-
-	return nil
-}
-
 func (d *sensorsImpl) createDB(config config.Config) error {
 	driver := config.Get("database", "driver").String("sqlite3")
 	source := config.Get("database", "source").String("../.build/sensors.db")
@@ -431,7 +393,20 @@ func (d *sensorsImpl) createDB(config config.Config) error {
 }
 
 func (d *sensorsImpl) New(watcher *sensors.Watcher) (id string, err error) {
-	watcher.ID = "W_" + d.snode.Generate().String()
+	// the only chain is the default chain
+	if watcher.ChainID == 0 && len(d.chains) == 1 {
+		for chainID := range d.chains {
+			watcher.ChainID = chainID
+		}
+	}
+
+	chain, err := d.chain(watcher.ChainID)
+
+	if err != nil {
+		return "", err
+	}
+
+	watcher.ID = d.ids.Generate("W_")
 
 	watcher.Address = strings.ToLower(watcher.Address)
 
@@ -445,6 +420,13 @@ func (d *sensorsImpl) New(watcher *sensors.Watcher) (id string, err error) {
 		return "", err
 	}
 
+	// register the token metadata ahead of its transfers
+	if watcher.ERC20 {
+		if _, err := chain.token(watcher.Address); err != nil {
+			d.WarnF("register erc20 %s of watcher %s err: %s", watcher.Address, watcher.ID, err)
+		}
+	}
+
 	return watcher.ID, nil
 }
 
@@ -1,192 +1,1037 @@
 package core
 
 import (
+	"errors"
 	"io/ioutil"
-	"math/big"
-	"strings"
-	"sync"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
-	"github.com/openzknetwork/ethgo/rpc"
-	"github.com/openzknetwork/ethgo/tx"
-
-	"github.com/openzknetwork/ethgo"
-
-	"github.com/dynamicgo/orm"
 	"github.com/go-xorm/xorm"
-
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/laplacenetwork/eth-sensors/cacher"
+	schema "github.com/laplacenetwork/eth-sensors/db"
+	"github.com/laplacenetwork/eth-sensors/oracle"
+	"github.com/laplacenetwork/eth-sensors/sensorstest"
 	"github.com/stretchr/testify/require"
 
-	goconfig "github.com/dynamicgo/go-config"
-	"github.com/dynamicgo/go-config/source/file"
-	sensors "github.com/laplacenetwork/eth-sensors"
-	_ "github.com/laplacenetwork/eth-sensors/cacher"
-	_ "github.com/laplacenetwork/eth-sensors/storage"
-	"github.com/openzknetwork/ethgo/keystore"
+	_ "github.com/laplacenetwork/eth-sensors/source"
+	_ "github.com/mattn/go-sqlite3"
 )
 
-var sensor sensors.Sensor
-var key *keystore.Key
-var notifier *testNotifier
-var client *rpc.Client
+const waitTimeout = 10 * time.Second
 
-type testNotifier struct {
-	sync.Mutex
-	C map[string]chan *sensors.Order
+type hermetic struct {
+	node     *sensorstest.Node
+	notifier *sensorstest.Notifier
+	storage  *sensorstest.Storage
+	clock    *sensorstest.Clock
+	ids      *sensorstest.Sequence
+	sensor   sensors.Sensor
+	dir      string
+	database string
 }
 
-func (notifier *testNotifier) Notify(receiver *sensors.Watcher, order *sensors.Order) error {
-	notifier.Lock()
-	c, ok := notifier.C[strings.ToLower(receiver.Key)]
-	notifier.Unlock()
+// newHermetic create sensor connected to the fake node, values create the override config entries
+func newHermetic(t *testing.T, values func(node *sensorstest.Node) map[string]interface{}) *hermetic {
+	dir, err := ioutil.TempDir("", "eth-sensors")
+
+	require.NoError(t, err)
+
+	database := filepath.Join(dir, "sensors.db")
+
+	db, err := xorm.NewEngine("sqlite3", database)
+
+	require.NoError(t, err)
+
+	require.NoError(t, schema.Migrate(db, schema.Latest()))
+
+	db.Close()
 
-	if ok {
-		c <- order
-	} else {
-		println("unknown watcher", receiver.Key)
+	h := &hermetic{
+		node:     sensorstest.NewNode(),
+		notifier: sensorstest.NewNotifier(),
+		storage:  sensorstest.NewStorage(),
+		clock:    sensorstest.NewClock(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)),
+		ids:      sensorstest.NewSequence(),
+		dir:      dir,
+		database: database,
 	}
 
-	return nil
+	var overrides map[string]interface{}
+
+	if values != nil {
+		overrides = values(h.node)
+	}
+
+	h.sensor = h.replica(t, overrides)
+
+	return h
 }
 
-func (notifier *testNotifier) Listen(key string) <-chan *sensors.Order {
-	notifier.Lock()
-	defer notifier.Unlock()
+// replica create sensor sharing the fake node, database, storage, notifier, id generator and clock
+func (h *hermetic) replica(t *testing.T, overrides map[string]interface{}) sensors.Sensor {
+	conf, err := sensorstest.Config(h.node, h.database, overrides)
+
+	require.NoError(t, err)
 
-	c := make(chan *sensors.Order, 1000)
+	sensor, err := sensors.New(
+		conf,
+		sensors.WithNotifier(h.notifier.Creator()),
+		sensors.WithStorage(h.storage.Creator()),
+		sensors.WithCacher(cacher.New),
+		sensors.WithPriceOracle(oracle.New),
+		sensors.WithIDGenerator(h.ids),
+		sensors.WithClock(h.clock),
+	)
 
-	notifier.C[key] = c
+	require.NoError(t, err)
 
-	return c
+	return sensor
 }
 
-func init() {
-	config := goconfig.NewConfig()
+func (h *hermetic) close() {
+	h.node.Close()
+	os.RemoveAll(h.dir)
+}
 
-	err := config.Load(file.NewSource(file.WithPath("../../conf/sensor.json")))
+func TestHermeticTransfer(t *testing.T) {
+	h := newHermetic(t, nil)
+	defer h.close()
 
-	if err != nil {
-		panic(err)
-	}
+	_, err := h.sensor.New(&sensors.Watcher{
+		Key:     "receiver",
+		Name:    "receiver",
+		Address: "0x00000000000000000000000000000000000000a1",
+	})
 
-	driver := config.Get("database", "driver").String("sqlite3")
-	source := config.Get("database", "source").String("../.build/sensors.db")
+	require.NoError(t, err)
 
-	db, err := xorm.NewEngine(driver, source)
+	tx := sensorstest.Transfer("0x00000000000000000000000000000000000000b1", "0x00000000000000000000000000000000000000a1", "0xde0b6b3a7640000")
 
-	if err != nil {
-		panic(err)
-	}
+	block := h.node.Mine(tx)
 
-	if err := orm.Sync(db); err != nil {
-		panic(err)
+	order, err := h.notifier.Wait("receiver", tx.Hash, sensors.StatusRunning, waitTimeout)
+
+	require.NoError(t, err)
+	require.Equal(t, block.Number, order.CommitBlock)
+	require.Equal(t, "0xde0b6b3a7640000", order.Value)
+
+	h.node.MineEmpty(3)
+
+	order, err = h.notifier.Wait("receiver", tx.Hash, sensors.StatusSucceed, waitTimeout)
+
+	require.NoError(t, err)
+	require.True(t, order.ConfirmBlock > block.Number)
+
+	saved, err := h.sensor.Order(tx.Hash)
+
+	require.NoError(t, err)
+	require.Equal(t, sensors.StatusSucceed, saved.Status)
+
+	histories, err := h.sensor.History(tx.Hash)
+
+	require.NoError(t, err)
+	require.Len(t, histories, 2)
+	require.Equal(t, sensors.StatusCreated, histories[0].From)
+	require.Equal(t, sensors.StatusRunning, histories[0].To)
+	require.Equal(t, block.Number, histories[0].Block)
+	require.Equal(t, sensors.StatusSucceed, histories[1].To)
+	require.Equal(t, order.ConfirmBlock, histories[1].Block)
+
+	// the terminal order never go back to running
+	_, err = saved.Transit(sensors.StatusRunning, 0, time.Now(), "")
+
+	require.IsType(t, &sensors.TransitionError{}, err)
+	require.Equal(t, sensors.StatusSucceed, saved.Status)
+}
+
+func TestHermeticAdmin(t *testing.T) {
+	h := newHermetic(t, nil)
+	defer h.close()
+
+	receiver := "0x00000000000000000000000000000000000000aa"
+
+	_, err := h.sensor.New(&sensors.Watcher{Key: "receiver", Address: receiver})
+
+	require.NoError(t, err)
+
+	tx := sensorstest.Transfer("0x00000000000000000000000000000000000000ba", receiver, "0x1")
+
+	block := h.node.Mine(tx)
+	h.node.MineEmpty(3)
+
+	_, err = h.notifier.Wait("receiver", tx.Hash, sensors.StatusSucceed, waitTimeout)
+
+	require.NoError(t, err)
+
+	head := h.node.Head().Number
+
+	deadline := time.Now().Add(waitTimeout)
+
+	for {
+		statuses, err := h.sensor.Status()
+
+		require.NoError(t, err)
+		require.Len(t, statuses, 1)
+
+		if statuses[0].Block == head {
+			require.Equal(t, int64(1), statuses[0].ChainID)
+			require.Equal(t, 0, statuses[0].Unconfirmed)
+			require.False(t, statuses[0].NotifierFailing)
+			break
+		}
+
+		require.True(t, time.Now().Before(deadline), "process head timeout")
+
+		time.Sleep(10 * time.Millisecond)
 	}
 
-	client = rpc.NewClient(config.Get("ethnode").String("http://localhost:8545"))
+	notified := len(h.notifier.Notifications())
+
+	replayed, err := h.sensor.Replay(1, block.Number, block.Number)
+
+	require.NoError(t, err)
+	require.Equal(t, 1, replayed)
+	require.Len(t, h.notifier.Notifications(), notified+1)
+
+	// the receipt reverted after the confirmation
+	h.node.Revert(tx.Hash)
+
+	// the failed update notify nothing
+	h.storage.Fail(errors.New("storage down"))
+
+	_, err = h.sensor.Recheck(tx.Hash)
+
+	require.Error(t, err)
+	require.Len(t, h.notifier.Notifications(), notified+1)
+
+	h.storage.Fail(nil)
+
+	// the order saved by another replica meanwhile is reloaded and rechecked
+	h.storage.Conflict(1)
+
+	order, err := h.sensor.Recheck(tx.Hash)
+
+	require.NoError(t, err)
+	require.Equal(t, sensors.StatusFailed, order.Status)
+	require.Equal(t, sensors.FailureReverted, order.FailureReason)
+
+	notifications := h.notifier.Notifications()
+
+	require.Len(t, notifications, notified+2)
+	require.Equal(t, sensors.StatusFailed, notifications[len(notifications)-1].Order.Status)
+
+	saved, err := h.sensor.Order(tx.Hash)
+
+	require.NoError(t, err)
+	require.Equal(t, sensors.StatusFailed, saved.Status)
+
+	histories, err := h.sensor.History(tx.Hash)
+
+	require.NoError(t, err)
+	require.Equal(t, "recheck", histories[len(histories)-1].Reason)
+}
+
+func TestHermeticRevertedTransfer(t *testing.T) {
+	h := newHermetic(t, nil)
+	defer h.close()
+
+	_, err := h.sensor.New(&sensors.Watcher{
+		Key:     "sender",
+		Address: "0x00000000000000000000000000000000000000b2",
+	})
+
+	require.NoError(t, err)
+
+	tx := sensorstest.Transfer("0x00000000000000000000000000000000000000b2", "0x00000000000000000000000000000000000000a2", "0x1")
+
+	// Error("insufficient balance")
+	h.node.SetCall(tx.To, tx.Input, "revert:0x08c379a0"+
+		"0000000000000000000000000000000000000000000000000000000000000020"+
+		"0000000000000000000000000000000000000000000000000000000000000014"+
+		"696e73756666696369656e742062616c616e6365000000000000000000000000")
+
+	h.node.Revert(tx.Hash)
+	h.node.Mine(tx)
+	h.node.MineEmpty(3)
+
+	order, err := h.notifier.Wait("sender", tx.Hash, sensors.StatusFailed, waitTimeout)
+
+	require.NoError(t, err)
+	require.Equal(t, sensors.FailureReverted, order.FailureReason)
+	require.Equal(t, "insufficient balance", order.RevertReason)
+}
+
+func TestHermeticReplacedTransfer(t *testing.T) {
+	h := newHermetic(t, nil)
+	defer h.close()
+
+	receiver := "0x00000000000000000000000000000000000000a8"
+
+	_, err := h.sensor.New(&sensors.Watcher{Key: "receiver", Address: receiver})
+
+	require.NoError(t, err)
 
-	sensors.RegisterNotifier("test-notifier", func(config goconfig.Config) (sensors.Notifier, error) {
-		notifier = &testNotifier{
-			C: make(map[string]chan *sensors.Order),
+	tx := sensorstest.Transfer("0x00000000000000000000000000000000000000b8", receiver, "0x1")
+
+	h.node.Mine(tx)
+
+	_, err = h.notifier.Wait("receiver", tx.Hash, sensors.StatusRunning, waitTimeout)
+
+	require.NoError(t, err)
+
+	// the reorged tx is forgotten by the node after another tx of the sender took its nonce
+	replacement := sensorstest.Transfer(tx.From, "0x00000000000000000000000000000000000000c8", "0x1")
+	replacement.Nonce = tx.Nonce
+
+	h.node.Reorg(1, []*sensorstest.Transaction{replacement})
+	h.node.MineEmpty(3)
+
+	order, err := h.notifier.Wait("receiver", tx.Hash, sensors.StatusFailed, waitTimeout)
+
+	require.NoError(t, err)
+	require.Equal(t, sensors.FailureReplaced, order.FailureReason)
+	require.Equal(t, tx.Nonce, order.Nonce)
+}
+
+func TestHermeticERC20Transfer(t *testing.T) {
+	h := newHermetic(t, nil)
+	defer h.close()
+
+	token := "0x00000000000000000000000000000000000000c3"
+	receiver := "0x00000000000000000000000000000000000000a3"
+
+	h.node.SetToken(token, "Test Token", "TT", 2)
+
+	_, err := h.sensor.New(&sensors.Watcher{
+		Key:     "token",
+		Address: token,
+		ERC20:   true,
+	})
+
+	require.NoError(t, err)
+
+	_, err = h.sensor.New(&sensors.Watcher{
+		Key:     "receiver",
+		Address: receiver,
+	})
+
+	require.NoError(t, err)
+
+	// the token registered by the watcher creation
+	require.Equal(t, 3, h.node.Calls("eth_call"))
+
+	tx := sensorstest.ERC20Transfer("0x00000000000000000000000000000000000000b3", token, receiver, "0x96")
+
+	h.node.Mine(tx)
+	h.node.MineEmpty(3)
+
+	order, err := h.notifier.Wait("receiver", tx.Hash, sensors.StatusSucceed, waitTimeout)
+
+	require.NoError(t, err)
+	require.Equal(t, token, order.Token)
+	require.Equal(t, "TT", order.Symbol)
+	require.Equal(t, "1.5", order.Amount)
+	require.Equal(t, 3, h.node.Calls("eth_call"))
+}
+
+func TestHermeticERC20Retry(t *testing.T) {
+	h := newHermetic(t, nil)
+	defer h.close()
+
+	token := "0x00000000000000000000000000000000000000c4"
+	receiver := "0x00000000000000000000000000000000000000a4"
+
+	h.node.SetToken(token, "Test Token", "TT", 2)
+
+	_, err := h.sensor.New(&sensors.Watcher{Key: "receiver", Address: receiver})
+
+	require.NoError(t, err)
+
+	// the block is retried until the token metadata fetched
+	h.node.Fail("eth_call", 1, "node busy")
+
+	tx := sensorstest.ERC20Transfer("0x00000000000000000000000000000000000000b4", token, receiver, "0x96")
+
+	h.node.Mine(tx)
+	h.node.MineEmpty(3)
+
+	order, err := h.notifier.Wait("receiver", tx.Hash, sensors.StatusSucceed, waitTimeout)
+
+	require.NoError(t, err)
+	require.Equal(t, token, order.Token)
+	require.Equal(t, "TT", order.Symbol)
+	require.Equal(t, "1.5", order.Amount)
+}
+
+func TestHermeticFiatValue(t *testing.T) {
+	h := newHermetic(t, func(node *sensorstest.Node) map[string]interface{} {
+		return map[string]interface{}{
+			"oracle": map[string]interface{}{
+				"currency": "USD",
+				"prices": map[string]interface{}{
+					"ETH": []map[string]interface{}{
+						{"time": "2018-12-31T00:00:00Z", "price": "250.5"},
+						{"time": "2019-02-01T00:00:00Z", "price": "300"},
+					},
+				},
+			},
+		}
+	})
+	defer h.close()
+
+	receiver := "0x00000000000000000000000000000000000000a9"
+
+	_, err := h.sensor.New(&sensors.Watcher{Key: "receiver", Address: receiver})
+
+	require.NoError(t, err)
+
+	tx := sensorstest.Transfer("0x00000000000000000000000000000000000000b9", receiver, "0x14d1120d7b160000")
+
+	h.node.Mine(tx)
+
+	order, err := h.notifier.Wait("receiver", tx.Hash, sensors.StatusRunning, waitTimeout)
+
+	require.NoError(t, err)
+	require.Equal(t, "ETH", order.Symbol)
+	require.Equal(t, "1.5", order.Amount)
+	require.Equal(t, "375.75", order.FiatValue)
+	require.Equal(t, "USD", order.FiatCurrency)
+}
+
+func TestHermeticOrderSnapshot(t *testing.T) {
+	h := newHermetic(t, nil)
+	defer h.close()
+
+	id, err := h.sensor.New(&sensors.Watcher{
+		Key:     "snapshot",
+		Address: "0x00000000000000000000000000000000000000A4",
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "W_1", id)
+
+	tx := sensorstest.Transfer("0x00000000000000000000000000000000000000b4", "0x00000000000000000000000000000000000000a4", "0x2")
+
+	block := h.node.Mine(tx)
+
+	order, err := h.notifier.Wait("snapshot", tx.Hash, sensors.StatusRunning, waitTimeout)
+
+	require.NoError(t, err)
+
+	blockTime := time.Unix(block.Timestamp, 0)
+
+	require.Equal(t, sensors.Order{
+		ID:           "O_2",
+		ChainID:      1,
+		TX:           tx.Hash,
+		PendingBlock: block.Number,
+		CommitBlock:  block.Number,
+		ConfirmBlock: -1,
+		Status:       sensors.StatusRunning,
+		CreateTime:   h.clock.Now(),
+		PendingTime:  blockTime,
+		CommitTime:   blockTime,
+		From:         tx.From,
+		To:           tx.To,
+		Value:        "0x2",
+		Code:         "0x",
+		GasLimits:    "0x0",
+		GasPrice:     tx.GasPrice,
+		ConfirmDepth: 1,
+		TimeoutDepth: 10,
+		Symbol:       "ETH",
+		Amount:       "0.000000000000000002",
+		Nonce:        tx.Nonce,
+	}, *order)
+}
+
+func TestHermeticMultiChain(t *testing.T) {
+	polygon := sensorstest.NewNode()
+	defer polygon.Close()
+
+	h := newHermetic(t, func(node *sensorstest.Node) map[string]interface{} {
+		return map[string]interface{}{
+			"chains": map[string]interface{}{
+				"mainnet": map[string]interface{}{
+					"chainid": 1,
+					"ethnode": node.URL(),
+				},
+				"polygon": map[string]interface{}{
+					"chainid": 137,
+					"ethnode": polygon.URL(),
+					"cacher": map[string]interface{}{
+						"order": map[string]interface{}{
+							"confirmed": 2,
+							"timeout":   10,
+						},
+					},
+				},
+			},
 		}
+	})
+	defer h.close()
+
+	address := "0x00000000000000000000000000000000000000a5"
 
-		return notifier, nil
+	_, err := h.sensor.New(&sensors.Watcher{
+		Key:     "polygon",
+		ChainID: 137,
+		Address: address,
 	})
 
-	sensor, err = sensors.New(config)
+	require.NoError(t, err)
 
-	if err != nil {
-		panic(err)
-	}
+	_, err = h.sensor.New(&sensors.Watcher{
+		Key:     "unknown",
+		ChainID: 56,
+		Address: address,
+	})
 
-	// load keystore
+	require.Equal(t, sensors.ErrChainNotFound, err)
 
-	buff, err := ioutil.ReadFile("../../conf/keystore/1.json")
+	mainnetTx := sensorstest.Transfer("0x00000000000000000000000000000000000000b5", address, "0x1")
+	polygonTx := sensorstest.Transfer("0x00000000000000000000000000000000000000b5", address, "0x2")
 
-	if err != nil {
-		panic(err)
+	h.node.Mine(mainnetTx)
+	h.node.MineEmpty(3)
+
+	polygon.Mine(polygonTx)
+	polygon.MineEmpty(4)
+
+	order, err := h.notifier.Wait("polygon", polygonTx.Hash, sensors.StatusSucceed, waitTimeout)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(137), order.ChainID)
+
+	for _, notification := range h.notifier.Notifications() {
+		require.NotEqual(t, mainnetTx.Hash, notification.Order.TX)
 	}
+}
+
+func TestHermeticWebSocket(t *testing.T) {
+	h := newHermetic(t, func(node *sensorstest.Node) map[string]interface{} {
+		return map[string]interface{}{
+			"websocket": map[string]interface{}{
+				"url":       node.WSURL(),
+				"start":     1,
+				"reconnect": "50ms",
+				"poll":      "1h",
+			},
+		}
+	})
+	defer h.close()
+
+	address := "0x00000000000000000000000000000000000000a6"
+
+	_, err := h.sensor.New(&sensors.Watcher{
+		Key:     "ws",
+		Address: address,
+	})
 
-	key, err = keystore.ReadKeyStore(buff, "test")
+	require.NoError(t, err)
+
+	first := sensorstest.Transfer("0x00000000000000000000000000000000000000b6", address, "0x1")
+
+	h.node.Mine(first)
+
+	_, err = h.notifier.Wait("ws", first.Hash, sensors.StatusRunning, waitTimeout)
+
+	require.NoError(t, err)
 
-	if err != nil {
-		panic(err)
+	// blocks mined while the subscription is down are filled over http after reconnecting
+	h.node.DropSubscriptions()
+
+	second := sensorstest.Transfer("0x00000000000000000000000000000000000000b6", address, "0x2")
+
+	h.node.Mine(second)
+	h.node.MineEmpty(3)
+
+	_, err = h.notifier.Wait("ws", second.Hash, sensors.StatusSucceed, waitTimeout)
+
+	require.NoError(t, err)
+	require.True(t, h.node.Calls("eth_subscribe") >= 2)
+}
+
+func TestHermeticWebSocketResume(t *testing.T) {
+	websocket := func(node *sensorstest.Node) map[string]interface{} {
+		return map[string]interface{}{
+			"websocket": map[string]interface{}{
+				"url":       node.WSURL(),
+				"reconnect": "50ms",
+				"poll":      "50ms",
+			},
+		}
 	}
 
+	h := newHermetic(t, websocket)
+	defer h.close()
+
+	address := "0x00000000000000000000000000000000000000ae"
+
+	_, err := h.sensor.New(&sensors.Watcher{
+		Key:     "resume",
+		Address: address,
+	})
+
+	require.NoError(t, err)
+
+	first := sensorstest.Transfer("0x00000000000000000000000000000000000000be", address, "0x1")
+
+	h.node.Mine(first)
+
+	_, err = h.notifier.Wait("resume", first.Hash, sensors.StatusRunning, waitTimeout)
+
+	require.NoError(t, err)
+
+	// the stopped sensor miss the blocks mined while down
+	require.NoError(t, h.sensor.(*sensorsImpl).db.Close())
+
+	second := sensorstest.Transfer("0x00000000000000000000000000000000000000be", address, "0x2")
+
+	h.node.Mine(second)
+	h.node.MineEmpty(3)
+
+	// the restarted sensor resume from the persisted cursor instead of the chain head
+	h.replica(t, websocket(h.node))
+
+	_, err = h.notifier.Wait("resume", second.Hash, sensors.StatusSucceed, waitTimeout)
+
+	require.NoError(t, err)
+
+	// the block replacing the handled head is fetched again after its child broke the parent hash
+	third := sensorstest.Transfer("0x00000000000000000000000000000000000000be", address, "0x3")
+
+	h.node.Reorg(1, []*sensorstest.Transaction{third}, nil)
+
+	_, err = h.notifier.Wait("resume", third.Hash, sensors.StatusRunning, waitTimeout)
+
+	require.NoError(t, err)
 }
 
-func evalGasPrice() (*ethgo.Value, error) {
-	gasPrice, err := client.SuggestGasPrice()
+func TestHermeticFileReplay(t *testing.T) {
+	recorded := sensorstest.NewNode()
+	defer recorded.Close()
 
-	if err != nil {
-		return nil, err
-	}
+	address := "0x00000000000000000000000000000000000000a7"
 
-	return ethgo.NewValue(new(big.Float).SetInt(gasPrice), ethgo.Wei), nil
+	tx := sensorstest.Transfer("0x00000000000000000000000000000000000000b7", address, "0x3")
+
+	recorded.Mine(tx)
+	recorded.MineEmpty(3)
+
+	file, err := ioutil.TempFile("", "blocks")
+
+	require.NoError(t, err)
+
+	defer os.Remove(file.Name())
+
+	require.NoError(t, recorded.WriteBlocks(file))
+	require.NoError(t, file.Close())
+
+	h := newHermetic(t, func(node *sensorstest.Node) map[string]interface{} {
+		return map[string]interface{}{
+			// receipts are fetched from the recorded chain
+			"ethnode": recorded.URL(),
+			"source":  "file",
+			"file": map[string]interface{}{
+				"path": file.Name(),
+			},
+		}
+	})
+	defer h.close()
+
+	_, err = h.sensor.New(&sensors.Watcher{
+		Key:     "replay",
+		Address: address,
+	})
+
+	require.NoError(t, err)
+
+	// the file is replayed before the watcher created, replay it again
+	require.NoError(t, h.sensor.Rewind(1, 0))
+
+	_, err = h.notifier.Wait("replay", tx.Hash, sensors.StatusSucceed, waitTimeout)
+
+	require.NoError(t, err)
 }
 
-func transTest() (string, error) {
+func TestHermeticPolicy(t *testing.T) {
+	h := newHermetic(t, func(node *sensorstest.Node) map[string]interface{} {
+		return map[string]interface{}{
+			"policies": map[string]interface{}{
+				"native": []interface{}{
+					map[string]interface{}{"min": "0", "confirmed": 1},
+					map[string]interface{}{"min": "1000000000000000000", "confirmed": 4, "timeout": 30},
+				},
+			},
+		}
+	})
+	defer h.close()
+
+	small := "0x00000000000000000000000000000000000000a8"
+	strict := "0x00000000000000000000000000000000000000a9"
+
+	_, err := h.sensor.New(&sensors.Watcher{Key: "small", Address: small})
+
+	require.NoError(t, err)
+
+	_, err = h.sensor.New(&sensors.Watcher{Key: "strict", Address: strict, Confirmed: 2})
 
-	nonce, err := client.Nonce(key.Address)
+	require.NoError(t, err)
+
+	smallTx := sensorstest.Transfer("0x00000000000000000000000000000000000000b8", small, "0x1")
+	largeTx := sensorstest.Transfer("0x00000000000000000000000000000000000000b8", small, "0xde0b6b3a7640000")
+	strictTx := sensorstest.Transfer("0x00000000000000000000000000000000000000b9", strict, "0x1")
+
+	block := h.node.Mine(smallTx, largeTx, strictTx)
+
+	h.node.MineEmpty(6)
+
+	for tx, depth := range map[string]int64{smallTx.Hash: 1, largeTx.Hash: 4, strictTx.Hash: 2} {
+		watcher := "small"
+
+		if tx == strictTx.Hash {
+			watcher = "strict"
+		}
 
-	if err != nil {
-		return "", err
+		order, err := h.notifier.Wait(watcher, tx, sensors.StatusSucceed, waitTimeout)
+
+		require.NoError(t, err)
+		require.Equal(t, depth, order.ConfirmDepth)
+		require.Equal(t, block.Number+depth+1, order.ConfirmBlock)
 	}
+}
+
+func TestHermeticFinality(t *testing.T) {
+	h := newHermetic(t, func(node *sensorstest.Node) map[string]interface{} {
+		node.SetFinality(0, 0)
+
+		return map[string]interface{}{
+			"confirm": map[string]interface{}{
+				"tag": "finalized",
+			},
+		}
+	})
+	defer h.close()
+
+	receiver := "0x00000000000000000000000000000000000000aa"
+
+	_, err := h.sensor.New(&sensors.Watcher{Key: "receiver", Address: receiver})
+
+	require.NoError(t, err)
+
+	tx := sensorstest.Transfer("0x00000000000000000000000000000000000000ba", receiver, "0x1")
+
+	block := h.node.Mine(tx)
+
+	_, err = h.notifier.Wait("receiver", tx.Hash, sensors.StatusIncluded, waitTimeout)
+
+	require.NoError(t, err)
+
+	// the depth never confirm the included orders
+	h.node.MineEmpty(3)
+	h.node.SetFinality(block.Number, 0)
+	h.node.Mine()
+
+	_, err = h.notifier.Wait("receiver", tx.Hash, sensors.StatusSafe, waitTimeout)
+
+	require.NoError(t, err)
+
+	h.node.SetFinality(block.Number+1, block.Number+1)
+	h.node.Mine()
+
+	order, err := h.notifier.Wait("receiver", tx.Hash, sensors.StatusFinalized, waitTimeout)
+
+	require.NoError(t, err)
+	require.Equal(t, block.Number+1, order.ConfirmBlock)
+}
+
+func TestHermeticTimeTimeout(t *testing.T) {
+	h := newHermetic(t, nil)
+	defer h.close()
+
+	receiver := "0x00000000000000000000000000000000000000ab"
+
+	// the fake node mine blocks every 15 seconds
+	_, err := h.sensor.New(&sensors.Watcher{Key: "receiver", Address: receiver, Confirmed: 3, ConfirmTimeout: 20})
+
+	require.NoError(t, err)
+
+	tx := sensorstest.Transfer("0x00000000000000000000000000000000000000bb", receiver, "0x1")
+
+	block := h.node.Mine(tx)
+
+	h.node.MineEmpty(2)
+
+	order, err := h.notifier.Wait("receiver", tx.Hash, sensors.StatusFailed, waitTimeout)
+
+	require.NoError(t, err)
+	require.Equal(t, sensors.TimeoutTime, order.TimeoutReason)
+	require.Equal(t, sensors.FailureTimeout, order.FailureReason)
+	require.Equal(t, block.Number+2, order.ConfirmBlock)
+
+	// the node forgot the tx, the order stays timeout
+	h.node.Reorg(3)
+
+	order, err = h.sensor.Recheck(tx.Hash)
+
+	require.NoError(t, err)
+	require.Equal(t, sensors.StatusFailed, order.Status)
+	require.Equal(t, sensors.FailureTimeout, order.FailureReason)
+	require.Equal(t, sensors.TimeoutTime, order.TimeoutReason)
+}
+
+func TestHermeticVersionConflict(t *testing.T) {
+	h := newHermetic(t, nil)
+	defer h.close()
 
-	val := ethgo.NewValue(big.NewFloat(0.01), ethgo.Ether)
-	gasPrice, err := evalGasPrice()
+	receiver := "0x00000000000000000000000000000000000000ac"
 
-	if err != nil {
-		return "", err
+	_, err := h.sensor.New(&sensors.Watcher{Key: "receiver", Address: receiver})
+
+	require.NoError(t, err)
+
+	tx := sensorstest.Transfer("0x00000000000000000000000000000000000000bc", receiver, "0x1")
+
+	h.node.Mine(tx)
+
+	_, err = h.notifier.Wait("receiver", tx.Hash, sensors.StatusRunning, waitTimeout)
+
+	require.NoError(t, err)
+
+	// another replica confirmed the order first
+	replica, err := h.storage.Find(1, tx.Hash)
+
+	require.NoError(t, err)
+
+	history, err := replica.Transit(sensors.StatusSucceed, 99, time.Now(), "")
+
+	require.NoError(t, err)
+
+	replica.ConfirmBlock = 99
+
+	require.NoError(t, h.storage.Update(replica, history))
+
+	next := sensorstest.Transfer("0x00000000000000000000000000000000000000bc", receiver, "0x2")
+
+	h.node.MineEmpty(3)
+	h.node.Mine(next)
+	h.node.MineEmpty(3)
+
+	_, err = h.notifier.Wait("receiver", next.Hash, sensors.StatusSucceed, waitTimeout)
+
+	require.NoError(t, err)
+
+	saved, err := h.storage.Find(1, tx.Hash)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(99), saved.ConfirmBlock)
+	require.Equal(t, int64(2), saved.Version)
+
+	// the stale update fail with version error
+	stale := *saved
+	stale.Version = 1
+
+	require.Equal(t, sensors.ErrVersion, h.storage.Update(&stale))
+}
+
+func TestHermeticFailover(t *testing.T) {
+	leader := func(holder string) map[string]interface{} {
+		return map[string]interface{}{
+			"leader": map[string]interface{}{
+				"holder": holder,
+				"ttl":    "1m",
+				"renew":  "50ms",
+			},
+		}
 	}
 
-	gasLimits := big.NewInt(21000)
+	h := newHermetic(t, func(node *sensorstest.Node) map[string]interface{} {
+		return leader("A")
+	})
+	defer h.close()
+
+	follower := h.replica(t, leader("B"))
+
+	require.Equal(t, sensors.ErrNotLeader, follower.Rewind(1, 0))
+
+	_, err := follower.Replay(1, 0, 10)
+
+	require.Equal(t, sensors.ErrNotLeader, err)
+
+	receiver := "0x00000000000000000000000000000000000000ad"
+
+	// the follower serve the apis on the shared database
+	_, err = follower.New(&sensors.Watcher{Key: "receiver", Address: receiver})
+
+	require.NoError(t, err)
+
+	tx := sensorstest.Transfer("0x00000000000000000000000000000000000000bd", receiver, "0x1")
+
+	h.node.Mine(tx)
+
+	running, err := h.notifier.Wait("receiver", tx.Hash, sensors.StatusRunning, waitTimeout)
+
+	require.NoError(t, err)
+
+	// the leader lost the database and stop renewing, the follower take over after the lease expired
+	require.NoError(t, h.sensor.(*sensorsImpl).db.Close())
 
-	rawTx := tx.NewTx(nonce, key.Address, val, gasPrice, gasLimits, nil)
+	h.clock.Advance(2 * time.Minute)
 
-	err = rawTx.Sign(key.PrivateKey)
+	deadline := time.Now().Add(waitTimeout)
 
-	if err != nil {
-		return "", err
+	for !follower.(*sensorsImpl).isLeader() {
+		require.True(t, time.Now().Before(deadline), "follower take over timeout")
+
+		time.Sleep(10 * time.Millisecond)
 	}
 
-	data, err := rawTx.Encode()
+	require.False(t, h.sensor.(*sensorsImpl).isLeader())
+
+	// the former leader stop its source and drop the cached orders taken over by the follower
+	for {
+		statuses, err := h.sensor.Status()
+
+		require.NoError(t, err)
+		require.False(t, statuses[0].Leader)
+
+		if statuses[0].Unconfirmed == 0 {
+			break
+		}
 
-	if err != nil {
-		return "", err
+		require.True(t, time.Now().Before(deadline), "former leader resign timeout")
+
+		time.Sleep(10 * time.Millisecond)
 	}
 
-	return client.SendRawTransaction(data)
+	next := sensorstest.Transfer("0x00000000000000000000000000000000000000bd", receiver, "0x2")
+
+	h.node.MineEmpty(3)
+	h.node.Mine(next)
+	h.node.MineEmpty(3)
+
+	order, err := h.notifier.Wait("receiver", tx.Hash, sensors.StatusSucceed, waitTimeout)
+
+	require.NoError(t, err)
+	require.Equal(t, running.ID, order.ID)
+
+	_, err = h.notifier.Wait("receiver", next.Hash, sensors.StatusSucceed, waitTimeout)
+
+	require.NoError(t, err)
+
+	order, err = follower.Order(tx.Hash)
+
+	require.NoError(t, err)
+	require.Equal(t, sensors.StatusSucceed, order.Status)
 }
 
-func TestRegisterWatcher(t *testing.T) {
-	id, err := sensor.New(&sensors.Watcher{
-		Key:     "test",
-		Name:    "test",
-		Address: key.Address,
+func TestHermeticRelease(t *testing.T) {
+	leader := func(holder string) map[string]interface{} {
+		return map[string]interface{}{
+			"leader": map[string]interface{}{
+				"holder": holder,
+				"ttl":    "1m",
+				"renew":  "50ms",
+			},
+		}
+	}
+
+	h := newHermetic(t, func(node *sensorstest.Node) map[string]interface{} {
+		return leader("A")
 	})
+	defer h.close()
 
-	if err != nil && err != sensors.ErrWatcherExists {
-		require.NoError(t, err)
+	follower := h.replica(t, leader("B"))
+
+	receiver := "0x00000000000000000000000000000000000000af"
+
+	_, err := h.sensor.New(&sensors.Watcher{Key: "receiver", Address: receiver})
+
+	require.NoError(t, err)
+
+	// the closed leader release the lease, the follower take over before the lease expired
+	require.NoError(t, h.sensor.(sensors.ClosableSensor).Close())
+	require.False(t, h.sensor.(*sensorsImpl).isLeader())
+
+	deadline := time.Now().Add(waitTimeout)
+
+	for !follower.(*sensorsImpl).isLeader() {
+		require.True(t, time.Now().Before(deadline), "follower take over timeout")
+
+		time.Sleep(10 * time.Millisecond)
 	}
 
-	c := notifier.Listen("test")
+	tx := sensorstest.Transfer("0x00000000000000000000000000000000000000bf", receiver, "0x1")
 
-	println("create watcher", id)
+	h.node.Mine(tx)
+	h.node.MineEmpty(3)
 
-	tx, err := transTest()
+	_, err = h.notifier.Wait("receiver", tx.Hash, sensors.StatusSucceed, waitTimeout)
 
 	require.NoError(t, err)
+}
+
+func TestHermeticRetention(t *testing.T) {
+	h := newHermetic(t, func(node *sensorstest.Node) map[string]interface{} {
+		return map[string]interface{}{
+			"retention": map[string]interface{}{
+				"days":     1,
+				"interval": "50ms",
+			},
+		}
+	})
+	defer h.close()
+
+	receiver := "0x00000000000000000000000000000000000000ae"
+
+	_, err := h.sensor.New(&sensors.Watcher{Key: "receiver", Address: receiver})
+
+	require.NoError(t, err)
+
+	tx := sensorstest.Transfer("0x00000000000000000000000000000000000000be", receiver, "0x1")
+
+	h.node.Mine(tx)
+	h.node.MineEmpty(3)
+
+	_, err = h.notifier.Wait("receiver", tx.Hash, sensors.StatusSucceed, waitTimeout)
+
+	require.NoError(t, err)
+
+	// the terminal order expired after the retention days
+	h.clock.Advance(48 * time.Hour)
 
-	println(tx)
+	deadline := time.Now().Add(waitTimeout)
 
 	for {
-		order := <-c
+		order, err := h.storage.Find(1, tx.Hash)
 
-		require.NotEqual(t, order.Status, sensors.StatusFailed)
+		require.NoError(t, err)
 
-		if order.Status == sensors.StatusSucceed && order.TX == tx {
+		if order.Archived {
 			break
 		}
+
+		require.True(t, time.Now().Before(deadline), "archive order timeout")
+
+		time.Sleep(10 * time.Millisecond)
 	}
 
+	order, err := h.sensor.Order(tx.Hash)
+
+	require.NoError(t, err)
+	require.True(t, order.Archived)
+	require.Equal(t, sensors.StatusSucceed, order.Status)
+
+	_, err = h.sensor.Recheck(tx.Hash)
+
+	require.Equal(t, sensors.ErrOrderArchived, err)
+
+	histories, err := h.sensor.History(tx.Hash)
+
+	require.NoError(t, err)
+	require.Len(t, histories, 2)
 }
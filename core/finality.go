@@ -0,0 +1,173 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	config "github.com/dynamicgo/go-config"
+	sensors "github.com/laplacenetwork/eth-sensors"
+)
+
+// the block tags of the post-merge chains
+const (
+	tagSafe      = "safe"
+	tagFinalized = "finalized"
+)
+
+// finality confirm orders by the chain safe or finalized block tag instead of the confirmation depth,
+// the committed orders are INCLUDED, then SAFE and FINALIZED when the tagged block passes their commit block
+type finality struct {
+	tag string // empty for the depth confirmation
+}
+
+// newFinality load the chain config "confirm.tag", "safe" or "finalized"
+func newFinality(config config.Config) (*finality, error) {
+	tag := config.Get("confirm", "tag").String("")
+
+	switch tag {
+	case "", tagSafe, tagFinalized:
+	default:
+		return nil, fmt.Errorf("invalid confirm tag %s", tag)
+	}
+
+	return &finality{tag: tag}, nil
+}
+
+// committed the status of orders committed in block
+func (finality *finality) committed() sensors.Status {
+	if finality.tag == "" {
+		return sensors.StatusRunning
+	}
+
+	return sensors.StatusIncluded
+}
+
+// succeed the terminal status of succeed orders
+func (finality *finality) succeed() sensors.Status {
+	switch finality.tag {
+	case tagSafe:
+		return sensors.StatusSafe
+	case tagFinalized:
+		return sensors.StatusFinalized
+	}
+
+	return sensors.StatusSucceed
+}
+
+// succeeded check if the terminal status is succeed with any confirmation mode
+func succeeded(status sensors.Status) bool {
+	return status == sensors.StatusSucceed || status == sensors.StatusSafe || status == sensors.StatusFinalized
+}
+
+// tagged get the block number of tag
+func (chain *chainDetector) tagged(tag string) (int64, error) {
+	var block *struct {
+		Number string `json:"number"`
+	}
+
+	if err := chain.call("eth_getBlockByNumber", &block, tag, false); err != nil {
+		return 0, err
+	}
+
+	if block == nil {
+		return 0, fmt.Errorf("%s block not found", tag)
+	}
+
+	return strconv.ParseInt(strings.TrimPrefix(block.Number, "0x"), 16, 64)
+}
+
+// settle move the included orders forward by the chain tagged blocks, the failed settlement is
+// retried with the next block
+func (chain *chainDetector) settle(blockTime time.Time) {
+	if chain.finality.tag == "" {
+		return
+	}
+
+	finalized, err := chain.tagged(chain.finality.tag)
+
+	if err != nil {
+		chain.WarnF("get %s block err: %s", chain.finality.tag, err)
+		return
+	}
+
+	safe := int64(-1)
+
+	if chain.finality.tag == tagFinalized {
+		if safe, err = chain.tagged(tagSafe); err != nil {
+			chain.WarnF("get %s block err: %s", tagSafe, err)
+			return
+		}
+	}
+
+	safeOrders, finalizedOrders := chain.cacher.Finalize(safe, finalized)
+
+	if len(safeOrders) == 0 && len(finalizedOrders) == 0 {
+		return
+	}
+
+	succeed, err := chain.receipts(finalizedOrders)
+
+	if err != nil {
+		chain.ErrorF("settle orders receipt err: %s", err)
+		chain.recache(append(safeOrders, finalizedOrders...), nil)
+		return
+	}
+
+	var orders []*sensors.Order
+	var histories []*sensors.OrderHistory
+
+	// the safe orders wait for the finalized block
+	for _, order := range safeOrders {
+		history, err := order.Transit(sensors.StatusSafe, safe, blockTime, "")
+
+		// the order is kept cached and settled with the finalized block
+		if err != nil {
+			chain.ErrorF("skip safe order %s with tx %s err: %s", order.ID, order.TX, err)
+			continue
+		}
+
+		orders = append(orders, order)
+		histories = append(histories, history)
+	}
+
+	finalizedOrders, finalizedHistories := chain.transit(finalizedOrders, func(i int) sensors.Status {
+		if succeed[i] {
+			return chain.finality.succeed()
+		}
+
+		return sensors.StatusFailed
+	}, finalized, blockTime)
+
+	for _, order := range finalizedOrders {
+		chain.InfoF("%s order %s with tx %s block %d status %s", chain.finality.tag, order.ID, order.TX, finalized, order.Status)
+	}
+
+	orders = append(orders, finalizedOrders...)
+	histories = append(histories, finalizedHistories...)
+
+	for i, order := range orders {
+		if err := chain.fence(); err != nil {
+			chain.recache(orders[i:], histories[i:])
+			return
+		}
+
+		// the orders before are saved already
+		if err := chain.notify(order); err != nil {
+			chain.recache(orders[i:], histories[i:])
+			return
+		}
+
+		if err := chain.update(order, histories[i]); err != nil {
+			chain.ErrorF("save order %s err: %s", order.TX, err)
+			chain.recache(orders[i:], histories[i:])
+			return
+		}
+
+		// refresh the cached safe order version
+		if order.Status == sensors.StatusSafe && order.ConfirmBlock < 0 {
+			chain.cacher.Pend(order)
+		}
+	}
+}
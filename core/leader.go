@@ -0,0 +1,206 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	config "github.com/dynamicgo/go-config"
+	"github.com/dynamicgo/slf4go"
+	"github.com/dynamicgo/xorm-decorator"
+	"github.com/go-xorm/xorm"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/laplacenetwork/eth-sensors/metrics"
+)
+
+// leader elect the replica running the block sources and confirming orders by the lease row in the shared database,
+// the followers serve the read apis and take over after the lease expired
+type leader struct {
+	sync.Mutex
+	slf4go.Logger
+	db      *xorm.Engine
+	clock   sensors.Clock
+	name    string
+	holder  string
+	ttl     time.Duration
+	renew   time.Duration
+	leading bool
+	expire  time.Time     // the lease expire time seen by this replica, stop leading after it even if renew blocked
+	done    chan struct{} // closed to stop renewing the lease
+	stopped chan struct{} // closed after the renew loop exited
+}
+
+func newLeader(config config.Config, db *xorm.Engine, clock sensors.Clock) *leader {
+	hostname, _ := os.Hostname()
+
+	ttl := config.Get("leader", "ttl").Duration(30 * time.Second)
+
+	return &leader{
+		Logger:  slf4go.Get("sensors-leader"),
+		db:      db,
+		clock:   clock,
+		name:    config.Get("leader", "name").String("eth-sensors"),
+		holder:  config.Get("leader", "holder").String(fmt.Sprintf("%s-%d", hostname, os.Getpid())),
+		ttl:     ttl,
+		renew:   config.Get("leader", "renew").Duration(ttl / 3),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// isLeader check if this replica holds the unexpired lease
+func (leader *leader) isLeader() bool {
+	leader.Lock()
+	defer leader.Unlock()
+
+	return leader.leading && leader.clock.Now().Before(leader.expire)
+}
+
+// acquire take the lease if it is free or expired, or renew it if held by this replica
+func (leader *leader) acquire() (bool, error) {
+	now := leader.clock.Now()
+
+	lease := &sensors.Lease{
+		Name:   leader.name,
+		Holder: leader.holder,
+		Expire: millis(now.Add(leader.ttl)),
+	}
+
+	start := time.Now()
+
+	affected, err := leader.db.Where(`"name" = ? and ("holder" = ? or "expire" < ?)`, lease.Name, lease.Holder, millis(now)).
+		Cols("holder", "expire").Update(lease)
+
+	metrics.ObserveDB("core", "renew_lease", start, err)
+
+	if err != nil {
+		return false, err
+	}
+
+	if affected == 0 {
+		start = time.Now()
+
+		_, err = leader.db.InsertOne(lease)
+
+		metrics.ObserveDB("core", "insert_lease", start, err)
+
+		if decorator.DuplicateKey(leader.db, err) {
+			return false, nil
+		}
+
+		if err != nil {
+			return false, err
+		}
+	}
+
+	leader.Lock()
+	leader.expire = now.Add(leader.ttl)
+	leader.Unlock()
+
+	return true, nil
+}
+
+// campaign acquire or renew the lease, call elected before leading when this replica become the leader
+// and resigned after stepped down when this replica lost the lease
+func (leader *leader) campaign(elected func() error, resigned func()) {
+	ok, err := leader.acquire()
+
+	if err != nil {
+		leader.WarnF("acquire lease %s err: %s", leader.name, err)
+	}
+
+	leader.Lock()
+	leading := leader.leading
+	leader.Unlock()
+
+	if !ok {
+		if leading {
+			leader.WarnF("replica %s lost lease %s", leader.holder, leader.name)
+			leader.step(false)
+			resigned()
+		}
+
+		return
+	}
+
+	if leading {
+		return
+	}
+
+	if err := elected(); err != nil {
+		leader.ErrorF("replica %s take over lease %s err: %s", leader.holder, leader.name, err)
+		return
+	}
+
+	leader.InfoF("replica %s lead lease %s", leader.holder, leader.name)
+
+	leader.step(true)
+}
+
+func (leader *leader) step(leading bool) {
+	leader.Lock()
+	leader.leading = leading
+	leader.Unlock()
+
+	value := 0.0
+
+	if leading {
+		value = 1
+	}
+
+	metrics.Leader.WithLabelValues(leader.name).Set(value)
+}
+
+// run renew the lease until released
+func (leader *leader) run(elected func() error, resigned func()) {
+	defer close(leader.stopped)
+
+	ticker := time.NewTicker(leader.renew)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			leader.campaign(elected, resigned)
+		case <-leader.done:
+			return
+		}
+	}
+}
+
+// release stop renewing the lease, call resigned if leading and delete the lease row held by this replica,
+// the other replicas take over without waiting for the lease expired
+func (leader *leader) release(resigned func()) error {
+	close(leader.done)
+	<-leader.stopped
+
+	leader.Lock()
+	leading := leader.leading
+	leader.Unlock()
+
+	if !leading {
+		return nil
+	}
+
+	leader.step(false)
+	resigned()
+
+	start := time.Now()
+
+	_, err := leader.db.Where(`"name" = ? and "holder" = ?`, leader.name, leader.holder).Delete(&sensors.Lease{})
+
+	metrics.ObserveDB("core", "release_lease", start, err)
+
+	if err != nil {
+		return err
+	}
+
+	leader.InfoF("replica %s released lease %s", leader.holder, leader.name)
+
+	return nil
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
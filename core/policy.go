@@ -0,0 +1,136 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/dynamicgo/fixed"
+	config "github.com/dynamicgo/go-config"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/openzknetwork/ethgo/erc20"
+)
+
+// nativeAsset the policy asset name of the chain native coin
+const nativeAsset = "native"
+
+// tier the confirmation depth applied to transfers with amount not less than Min
+type tier struct {
+	Min       string `json:"min"`       // decimal or 0x prefixed hex amount in the minimal unit
+	Confirmed int64  `json:"confirmed"` // confirmation blocks
+	Timeout   int64  `json:"timeout"`   // pending timeout blocks
+	min       *big.Int
+}
+
+// policy choose the order confirmation depth by watcher requirement and transferred asset tiers,
+// the deepest requirement wins, the cacher default depth is recorded if nothing required
+type policy struct {
+	assets        map[string][]*tier // tiers indexed by lower case token address or "native", sorted by min desc
+	confirmBlocks int64              // the cacher default confirmation blocks
+	timeoutBlocks int64              // the cacher default pending timeout blocks
+}
+
+// newPolicy load asset tiers from config "policies" section, and the default depth from cacher config "order" section:
+//
+//	"policies": {
+//		"native": [{"min": "0", "confirmed": 1}, {"min": "1000000000000000000", "confirmed": 12}],
+//		"0xdac17f958d2ee523a2206206994597c13d831ec7": [{"min": "0", "confirmed": 6, "timeout": 120}]
+//	}
+func newPolicy(config config.Config, cacherConfig config.Config) (*policy, error) {
+	assets := make(map[string][]*tier)
+
+	if hasSection(config, "policies") {
+		if err := config.Get("policies").Scan(&assets); err != nil {
+			return nil, fmt.Errorf("load policies err: %s", err)
+		}
+	}
+
+	policy := &policy{
+		assets:        make(map[string][]*tier),
+		confirmBlocks: int64(cacherConfig.Get("order", "confirmed").Int(1)),
+		timeoutBlocks: int64(cacherConfig.Get("order", "timeout").Int(60)),
+	}
+
+	for asset, tiers := range assets {
+		for _, tier := range tiers {
+			min, ok := new(big.Int).SetString(tier.Min, 0)
+
+			if !ok {
+				return nil, fmt.Errorf("policy %s invalid min amount %s", asset, tier.Min)
+			}
+
+			tier.min = min
+		}
+
+		sort.Slice(tiers, func(i, j int) bool {
+			return tiers[i].min.Cmp(tiers[j].min) > 0
+		})
+
+		policy.assets[strings.ToLower(asset)] = tiers
+	}
+
+	return policy, nil
+}
+
+// apply record the chosen confirmation and timeout depth on the order, the longest timeout seconds of watchers win
+func (policy *policy) apply(order *sensors.Order, watchers []*sensors.Watcher) {
+	order.ConfirmDepth = 0
+	order.TimeoutDepth = 0
+	order.PendingTimeout = 0
+	order.ConfirmTimeout = 0
+
+	for _, watcher := range watchers {
+		order.ConfirmDepth = maxDepth(order.ConfirmDepth, watcher.Confirmed)
+		order.TimeoutDepth = maxDepth(order.TimeoutDepth, watcher.Timeout)
+		order.PendingTimeout = maxDepth(order.PendingTimeout, watcher.PendingTimeout)
+		order.ConfirmTimeout = maxDepth(order.ConfirmTimeout, watcher.ConfirmTimeout)
+	}
+
+	asset, amount := transfer(order)
+
+	for _, tier := range policy.assets[asset] {
+		if amount.Cmp(tier.min) >= 0 {
+			order.ConfirmDepth = maxDepth(order.ConfirmDepth, tier.Confirmed)
+			order.TimeoutDepth = maxDepth(order.TimeoutDepth, tier.Timeout)
+			break
+		}
+	}
+
+	if order.ConfirmDepth == 0 {
+		order.ConfirmDepth = policy.confirmBlocks
+	}
+
+	if order.TimeoutDepth == 0 {
+		order.TimeoutDepth = policy.timeoutBlocks
+	}
+}
+
+// transfer get the transferred asset and amount of order, erc20 transfer calls transfer the token of the called contract
+func transfer(order *sensors.Order) (string, *big.Int) {
+	code := strings.TrimPrefix(order.Code, "0x")
+
+	if strings.HasPrefix(code, erc20.TransferID) && len(code) == len(erc20.TransferID)+128 {
+		amount, ok := new(big.Int).SetString(code[len(erc20.TransferID)+64:], 16)
+
+		if ok {
+			return strings.ToLower(order.To), amount
+		}
+	}
+
+	value, err := fixed.FromHex(order.Value, nativeDecimals)
+
+	if err != nil {
+		return nativeAsset, new(big.Int)
+	}
+
+	return nativeAsset, value.ValueBigInteger()
+}
+
+func maxDepth(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+
+	return b
+}
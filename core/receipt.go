@@ -0,0 +1,229 @@
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/laplacenetwork/eth-sensors/rpcpool"
+)
+
+// the selectors of the solidity builtin errors
+const (
+	errorSelector = "08c379a0" // Error(string)
+	panicSelector = "4e487b71" // Panic(uint256)
+)
+
+type txReceipt struct {
+	BlockNumber string `json:"blockNumber"`
+	Status      string `json:"status"`
+	GasUsed     string `json:"gasUsed"`
+}
+
+type txObject struct {
+	From        string  `json:"from"`
+	To          string  `json:"to"`
+	Value       string  `json:"value"`
+	Gas         string  `json:"gas"`
+	Input       string  `json:"input"`
+	Nonce       string  `json:"nonce"`
+	BlockNumber *string `json:"blockNumber"`
+}
+
+// orderRecipt check the order tx receipt status, the failure reason is recorded on the failed order,
+// the timeout order stays timeout if the node know nothing about its tx
+func (chain *chainDetector) orderRecipt(order *sensors.Order) (bool, error) {
+	var receipt *txReceipt
+
+	if err := chain.call("eth_getTransactionReceipt", &receipt, order.TX); err != nil {
+		return false, err
+	}
+
+	timeout := order.TimeoutReason
+
+	if order.FailureReason != sensors.FailureTimeout {
+		timeout = ""
+	}
+
+	order.FailureReason = ""
+	order.RevertReason = ""
+	order.TimeoutReason = ""
+
+	if receipt != nil && receipt.Status != "0x0" {
+		return true, nil
+	}
+
+	var tx *txObject
+
+	if err := chain.call("eth_getTransactionByHash", &tx, order.TX); err != nil {
+		return false, err
+	}
+
+	if receipt == nil {
+		if tx == nil && timeout != "" {
+			order.FailureReason = sensors.FailureTimeout
+			order.TimeoutReason = timeout
+			return false, nil
+		}
+
+		reason, err := chain.missing(order, tx)
+
+		if err != nil {
+			return false, err
+		}
+
+		order.FailureReason = reason
+
+		return false, nil
+	}
+
+	if tx != nil && hexCmp(receipt.GasUsed, tx.Gas) >= 0 {
+		order.FailureReason = sensors.FailureOutOfGas
+		return false, nil
+	}
+
+	order.FailureReason = sensors.FailureReverted
+	order.RevertReason = chain.revert(order, tx, receipt.BlockNumber)
+
+	return false, nil
+}
+
+// missing get the failure reason of tx without receipt, the tx replaced if the sender nonce passed it,
+// the sender and nonce recorded on the order are checked if the node forgot the replaced tx
+func (chain *chainDetector) missing(order *sensors.Order, tx *txObject) (sensors.FailureReason, error) {
+	from, nonce := order.From, order.Nonce
+
+	if tx != nil {
+		if tx.BlockNumber != nil {
+			return sensors.FailureDropped, nil
+		}
+
+		from, nonce = tx.From, tx.Nonce
+	}
+
+	// the legacy orders without nonce
+	if nonce == "" {
+		return sensors.FailureDropped, nil
+	}
+
+	var count string
+
+	if err := chain.call("eth_getTransactionCount", &count, from, "latest"); err != nil {
+		return "", err
+	}
+
+	if hexCmp(count, nonce) > 0 {
+		return sensors.FailureReplaced, nil
+	}
+
+	return sensors.FailureDropped, nil
+}
+
+// revert re-execute the reverted tx with eth_call on the state before its block and decode the revert data
+func (chain *chainDetector) revert(order *sensors.Order, tx *txObject, blockNumber string) string {
+	call := map[string]string{
+		"from":  order.From,
+		"to":    order.To,
+		"value": order.Value,
+		"data":  order.Code,
+	}
+
+	if tx != nil {
+		call["gas"] = tx.Gas
+	}
+
+	block := "latest"
+
+	if number, err := strconv.ParseInt(strings.TrimPrefix(blockNumber, "0x"), 16, 64); err == nil && number > 0 {
+		block = "0x" + strconv.FormatInt(number-1, 16)
+	}
+
+	var result string
+
+	err := chain.call("eth_call", &result, call, block)
+
+	if err == nil {
+		chain.WarnF("re-execute reverted tx %s not revert", order.TX)
+		return ""
+	}
+
+	rpcErr, ok := err.(*rpcpool.Error)
+
+	if !ok {
+		chain.WarnF("re-execute reverted tx %s err: %s", order.TX, err)
+		return ""
+	}
+
+	var data string
+
+	if json.Unmarshal(rpcErr.Data, &data) != nil || data == "" || data == "0x" {
+		return rpcErr.Message
+	}
+
+	return decodeRevert(data)
+}
+
+// decodeRevert decode the revert data, Error(string) to the reason string, Panic(uint256) to the panic code,
+// the custom error data is kept in hex
+func decodeRevert(data string) string {
+	data = strings.TrimPrefix(data, "0x")
+
+	if len(data) < 8 {
+		return "0x" + data
+	}
+
+	switch data[:8] {
+	case errorSelector:
+		if reason, ok := decodeString(data[8:]); ok {
+			return reason
+		}
+	case panicSelector:
+		code, ok := new(big.Int).SetString(data[8:], 16)
+
+		if ok {
+			return "panic 0x" + code.Text(16)
+		}
+	}
+
+	return "0x" + data
+}
+
+// decodeString decode the abi encoded string in hex
+func decodeString(data string) (string, bool) {
+	buff, err := hex.DecodeString(data)
+
+	if err != nil || len(buff) < 64 {
+		return "", false
+	}
+
+	offset := new(big.Int).SetBytes(buff[:32])
+
+	if !offset.IsInt64() || offset.Int64()+32 > int64(len(buff)) {
+		return "", false
+	}
+
+	start := offset.Int64() + 32
+
+	length := new(big.Int).SetBytes(buff[start-32 : start])
+
+	if !length.IsInt64() || start+length.Int64() > int64(len(buff)) {
+		return "", false
+	}
+
+	return string(buff[start : start+length.Int64()]), true
+}
+
+// hexCmp compare two hex quantities, -1 if any is invalid
+func hexCmp(a, b string) int {
+	x, _ := new(big.Int).SetString(strings.TrimPrefix(a, "0x"), 16)
+	y, _ := new(big.Int).SetString(strings.TrimPrefix(b, "0x"), 16)
+
+	if x == nil || y == nil {
+		return -1
+	}
+
+	return x.Cmp(y)
+}
@@ -0,0 +1,23 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeRevert(t *testing.T) {
+	require.Equal(t, "insufficient balance", decodeRevert("0x08c379a0"+
+		"0000000000000000000000000000000000000000000000000000000000000020"+
+		"0000000000000000000000000000000000000000000000000000000000000014"+
+		"696e73756666696369656e742062616c616e6365000000000000000000000000"))
+
+	require.Equal(t, "panic 0x11", decodeRevert("0x4e487b71"+
+		"0000000000000000000000000000000000000000000000000000000000000011"))
+
+	// custom error kept in hex
+	require.Equal(t, "0xe450d38c0000", decodeRevert("0xe450d38c0000"))
+
+	// truncated Error(string) data
+	require.Equal(t, "0x08c379a000", decodeRevert("0x08c379a000"))
+}
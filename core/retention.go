@@ -0,0 +1,83 @@
+package core
+
+import (
+	"time"
+
+	config "github.com/dynamicgo/go-config"
+	"github.com/dynamicgo/slf4go"
+	"github.com/laplacenetwork/eth-sensors/metrics"
+)
+
+// retention archive the terminal orders older than the retention days in batches, only the leader replica runs it
+type retention struct {
+	slf4go.Logger
+	*sensorsImpl
+	age      time.Duration
+	interval time.Duration
+	batch    int
+}
+
+// newRetention create retention with config "retention" section, return nil if days not configured
+func newRetention(impl *sensorsImpl, config config.Config) *retention {
+	days := config.Get("retention", "days").Int(0)
+
+	if days <= 0 {
+		return nil
+	}
+
+	retention := &retention{
+		Logger:      slf4go.Get("sensors-retention"),
+		sensorsImpl: impl,
+		age:         time.Duration(days) * 24 * time.Hour,
+		interval:    config.Get("retention", "interval").Duration(time.Hour),
+		batch:       config.Get("retention", "batch").Int(1000),
+	}
+
+	if retention.batch < 1 {
+		retention.batch = 1
+	}
+
+	return retention
+}
+
+func (retention *retention) run() {
+	ticker := time.NewTicker(retention.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !retention.isLeader() {
+			continue
+		}
+
+		archived, err := retention.archive()
+
+		metrics.ArchiveRuns.WithLabelValues("core", metrics.Result(err)).Inc()
+
+		if err != nil {
+			retention.ErrorF("archive orders err: %s", err)
+		}
+
+		if archived > 0 {
+			retention.InfoF("archived orders %d", archived)
+		}
+	}
+}
+
+// archive move the terminal orders until no more expired, return the archived number
+func (retention *retention) archive() (int, error) {
+	before := retention.clock.Now().Add(-retention.age)
+
+	total := 0
+
+	for {
+		archived, err := retention.storage.Archive(before, retention.batch)
+
+		total += archived
+
+		metrics.ArchivedOrders.WithLabelValues("core").Add(float64(archived))
+
+		if err != nil || archived < retention.batch {
+			return total, err
+		}
+	}
+}
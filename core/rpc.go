@@ -0,0 +1,22 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/laplacenetwork/eth-sensors/metrics"
+	"github.com/laplacenetwork/eth-sensors/rpcpool"
+)
+
+// call the json rpc method not wrapped by the ethgo client, the json rpc error is returned as *rpcpool.Error
+func (chain *chainDetector) call(method string, result interface{}, params ...interface{}) (err error) {
+	start := time.Now()
+
+	defer func() {
+		metrics.ObserveRPC("core", chain.label, method, start, err)
+	}()
+
+	_, err = rpcpool.Call(context.Background(), chain.client, chain.url, method, result, params...)
+
+	return err
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	sensors "github.com/laplacenetwork/eth-sensors"
+)
+
+type syncState struct {
+	sync.RWMutex
+	block       int64
+	hash        string
+	blockTime   time.Time
+	processedAt time.Time
+	head        int64
+}
+
+func (state *syncState) chainHead(head int64) (lag int64) {
+	state.Lock()
+	defer state.Unlock()
+
+	state.head = head
+
+	return state.lag()
+}
+
+func (state *syncState) processed(block int64, hash string, blockTime time.Time, now time.Time) (lag int64) {
+	state.Lock()
+	defer state.Unlock()
+
+	state.block = block
+	state.hash = hash
+	state.blockTime = blockTime
+	state.processedAt = now
+
+	return state.lag()
+}
+
+// notifyState the last notification result shared by all chains
+type notifyState struct {
+	sync.RWMutex
+	err error
+}
+
+func (state *notifyState) notified(err error) {
+	state.Lock()
+	defer state.Unlock()
+
+	state.err = err
+}
+
+func (state *notifyState) lastError() error {
+	state.RLock()
+	defer state.RUnlock()
+
+	return state.err
+}
+
+func (state *syncState) lag() int64 {
+	if state.head < state.block {
+		return 0
+	}
+
+	return state.head - state.block
+}
+
+// trackedNotifier record the last notification result into sync state
+type trackedNotifier struct {
+	sensors.Notifier
+	state *notifyState
+}
+
+func (notifier *trackedNotifier) Notify(receiver *sensors.Watcher, order *sensors.Order) error {
+	err := notifier.Notifier.Notify(receiver, order)
+
+	notifier.state.notified(err)
+
+	return err
+}
+
+func (d *sensorsImpl) Status() ([]*sensors.SyncStatus, error) {
+	notifyErr := d.notifyState.lastError()
+
+	statuses := make([]*sensors.SyncStatus, 0, len(d.chains))
+
+	for _, chain := range d.chains {
+		status := chain.status()
+
+		if notifyErr != nil {
+			status.NotifierFailing = true
+			status.NotifierError = notifyErr.Error()
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ChainID < statuses[j].ChainID
+	})
+
+	return statuses, nil
+}
+
+func (chain *chainDetector) status() *sensors.SyncStatus {
+	chain.state.RLock()
+	defer chain.state.RUnlock()
+
+	status := &sensors.SyncStatus{
+		ChainID:       chain.id,
+		Block:         chain.state.block,
+		BlockHash:     chain.state.hash,
+		BlockTime:     chain.state.blockTime,
+		Head:          chain.state.head,
+		Lag:           chain.state.lag(),
+		LastProcessed: chain.state.processedAt,
+		Unconfirmed:   chain.cached().Size(),
+		Leader:        chain.isLeader(),
+	}
+
+	if !chain.state.blockTime.IsZero() {
+		status.LagSeconds = int64(chain.clock.Now().Sub(chain.state.blockTime).Seconds())
+	}
+
+	return status
+}
+
+// cached get the chain cacher replaced after the replica lost the lease
+func (chain *chainDetector) cached() sensors.OrderCacher {
+	chain.cacherLock.RLock()
+	defer chain.cacherLock.RUnlock()
+
+	return chain.cacher
+}
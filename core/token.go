@@ -0,0 +1,150 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/dynamicgo/xorm-decorator"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/laplacenetwork/eth-sensors/metrics"
+	"github.com/laplacenetwork/eth-sensors/rpcpool"
+)
+
+// the erc20 metadata method selectors
+const (
+	nameSelector     = "0x06fdde03" // name()
+	symbolSelector   = "0x95d89b41" // symbol()
+	decimalsSelector = "0x313ce567" // decimals()
+)
+
+// token get the erc20 token metadata from the registry, the unknown token is fetched from the contract and registered
+func (chain *chainDetector) token(address string) (*sensors.ERC20, error) {
+	address = strings.ToLower(address)
+
+	chain.tokensLock.Lock()
+	token, ok := chain.tokens[address]
+	chain.tokensLock.Unlock()
+
+	if ok {
+		return token, nil
+	}
+
+	token = new(sensors.ERC20)
+
+	start := time.Now()
+
+	ok, err := chain.db.Where(`"chain_i_d" = ? and "address" = ?`, chain.id, address).Get(token)
+
+	metrics.ObserveDB("core", "get_erc20", start, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		token, err = chain.fetchToken(address)
+
+		if err != nil {
+			return nil, err
+		}
+
+		start = time.Now()
+
+		_, err = chain.db.InsertOne(token)
+
+		metrics.ObserveDB("core", "insert_erc20", start, err)
+
+		// registered by another replica
+		if err != nil && !decorator.DuplicateKey(chain.db, err) {
+			return nil, err
+		}
+
+		chain.InfoF("register erc20 %s %s decimals %d", address, token.Symbol, token.Decimals)
+	}
+
+	chain.tokensLock.Lock()
+	chain.tokens[address] = token
+	chain.tokensLock.Unlock()
+
+	return token, nil
+}
+
+// tokenError the contract is not an erc20 token, its decimals are unknown forever
+type tokenError struct {
+	address string
+	reason  string
+}
+
+func (err *tokenError) Error() string {
+	return fmt.Sprintf("contract %s %s, not erc20 token", err.address, err.reason)
+}
+
+// fetchToken call the contract metadata methods, name and symbol are optional in erc20
+func (chain *chainDetector) fetchToken(address string) (*sensors.ERC20, error) {
+	result, err := chain.callToken(address, decimalsSelector)
+
+	// the contract reverted the call, the other errors are retried
+	if rpcErr, ok := err.(*rpcpool.Error); ok && (rpcErr.Code == 3 || strings.Contains(rpcErr.Message, "revert")) {
+		return nil, &tokenError{address: address, reason: fmt.Sprintf("decimals() err: %s", rpcErr)}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	decimals, ok := new(big.Int).SetString(strings.TrimPrefix(result, "0x"), 16)
+
+	if !ok || len(strings.TrimPrefix(result, "0x")) != 64 || decimals.Cmp(big.NewInt(255)) > 0 {
+		return nil, &tokenError{address: address, reason: fmt.Sprintf("decimals() return %s", result)}
+	}
+
+	token := &sensors.ERC20{
+		ChainID:    chain.id,
+		Address:    address,
+		Decimals:   decimals.Int64(),
+		CreateTime: chain.clock.Now(),
+	}
+
+	if result, err := chain.callToken(address, nameSelector); err == nil {
+		token.Name = decodeText(result)
+	}
+
+	if result, err := chain.callToken(address, symbolSelector); err == nil {
+		token.Symbol = decodeText(result)
+	}
+
+	return token, nil
+}
+
+func (chain *chainDetector) callToken(address string, selector string) (string, error) {
+	var result string
+
+	err := chain.call("eth_call", &result, map[string]string{
+		"to":   address,
+		"data": selector,
+	}, "latest")
+
+	return result, err
+}
+
+// decodeText decode the string returned by name or symbol, some early tokens return bytes32
+func decodeText(result string) string {
+	data := strings.TrimPrefix(result, "0x")
+
+	if len(data) == 64 {
+		buff, err := hex.DecodeString(data)
+
+		if err != nil {
+			return ""
+		}
+
+		return strings.TrimRight(string(buff), "\x00")
+	}
+
+	text, _ := decodeString(data)
+
+	return text
+}
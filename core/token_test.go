@@ -0,0 +1,19 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeText(t *testing.T) {
+	// bytes32 symbol of the early tokens
+	require.Equal(t, "MKR", decodeText("0x4d4b520000000000000000000000000000000000000000000000000000000000"))
+
+	require.Equal(t, "Dai", decodeText("0x"+
+		"0000000000000000000000000000000000000000000000000000000000000020"+
+		"0000000000000000000000000000000000000000000000000000000000000003"+
+		"4461690000000000000000000000000000000000000000000000000000000000"))
+
+	require.Equal(t, "", decodeText("0x"))
+}
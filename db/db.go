@@ -1,18 +1,190 @@
+// Package db the versioned schema migrations of the sensors tables,
+// the migrations run on sqlite3 and postgres only like the quoted raw sql of the sensors
 package db
 
 import (
-	"github.com/dynamicgo/orm"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dynamicgo/slf4go"
+	"github.com/go-xorm/xorm"
 	sensors "github.com/laplacenetwork/eth-sensors"
 )
 
-// Empty .
-type Empty struct {
+var logger = slf4go.Get("eth-sensors-migrate")
+
+// the drivers supporting the quoted identifiers, table renaming and DROP INDEX IF EXISTS used by the migrations
+var drivers = map[string]bool{
+	"sqlite3":  true,
+	"postgres": true,
+}
+
+// Schema the applied migration record
+type Schema struct {
+	Version int64     `xorm:"pk"`
+	Name    string    `xorm:""`
+	Applied time.Time `xorm:""`
+}
+
+// TableName .
+func (table *Schema) TableName() string {
+	return "eth_sensors_schema"
+}
+
+// Migration the versioned schema change, migrations are applied in version order and each runs in its own transaction
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(session *xorm.Session) error
+	Down    func(session *xorm.Session) error // nil if the migration can not be reverted
 }
 
-func init() {
-	orm.RegisterWithName("eth-sensors", func() []interface{} {
-		return []interface{}{
-			new(sensors.Watcher), new(sensors.Order), new(sensors.ERC20),
+var migrations []*Migration
+
+// Register add migration, panic if the version is registered
+func Register(migration *Migration) {
+	for _, registered := range migrations {
+		if registered.Version == migration.Version {
+			panic(fmt.Sprintf("duplicate migration version %d %s", migration.Version, migration.Name))
 		}
+	}
+
+	migrations = append(migrations, migration)
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
 	})
 }
+
+// Latest the latest registered migration version
+func Latest() int64 {
+	if len(migrations) == 0 {
+		return 0
+	}
+
+	return migrations[len(migrations)-1].Version
+}
+
+// Current the applied schema version, 0 if no migration applied
+func Current(engine *xorm.Engine) (int64, error) {
+	ok, err := engine.IsTableExist(new(Schema))
+
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	schema := new(Schema)
+
+	if _, err := engine.Desc("version").Get(schema); err != nil {
+		return 0, err
+	}
+
+	return schema.Version, nil
+}
+
+// Check return sensors.ErrSchemaOutdated if the schema is not at the latest version
+func Check(engine *xorm.Engine) error {
+	current, err := Current(engine)
+
+	if err != nil {
+		return err
+	}
+
+	if current != Latest() {
+		logger.ErrorF("database schema version %d, expect %d", current, Latest())
+		return sensors.ErrSchemaOutdated
+	}
+
+	return nil
+}
+
+// Migrate apply or revert the migrations until the schema reach version
+func Migrate(engine *xorm.Engine, version int64) error {
+	if !drivers[engine.DriverName()] {
+		return fmt.Errorf("migrations not support database driver %s, expect sqlite3 or postgres", engine.DriverName())
+	}
+
+	if err := engine.Sync2(new(Schema)); err != nil {
+		return err
+	}
+
+	current, err := Current(engine)
+
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		if migration.Version <= current || migration.Version > version {
+			continue
+		}
+
+		logger.InfoF("apply migration %d %s", migration.Version, migration.Name)
+
+		err := transaction(engine, func(session *xorm.Session) error {
+			if err := migration.Up(session); err != nil {
+				return err
+			}
+
+			_, err := session.InsertOne(&Schema{
+				Version: migration.Version,
+				Name:    migration.Name,
+				Applied: time.Now(),
+			})
+
+			return err
+		})
+
+		if err != nil {
+			return fmt.Errorf("apply migration %d %s err: %s", migration.Version, migration.Name, err)
+		}
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		migration := migrations[i]
+
+		if migration.Version > current || migration.Version <= version {
+			continue
+		}
+
+		if migration.Down == nil {
+			return fmt.Errorf("migration %d %s can not be reverted", migration.Version, migration.Name)
+		}
+
+		logger.InfoF("revert migration %d %s", migration.Version, migration.Name)
+
+		err := transaction(engine, func(session *xorm.Session) error {
+			if err := migration.Down(session); err != nil {
+				return err
+			}
+
+			_, err := session.Where(`"version" = ?`, migration.Version).Delete(new(Schema))
+
+			return err
+		})
+
+		if err != nil {
+			return fmt.Errorf("revert migration %d %s err: %s", migration.Version, migration.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// transaction run f in database transaction
+func transaction(engine *xorm.Engine, f func(session *xorm.Session) error) error {
+	session := engine.NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return err
+	}
+
+	if err := f(session); err != nil {
+		session.Rollback()
+		return err
+	}
+
+	return session.Commit()
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xorm/xorm"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestMigrate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eth-sensors")
+
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	engine, err := xorm.NewEngine("sqlite3", filepath.Join(dir, "sensors.db"))
+
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	require.Equal(t, sensors.ErrSchemaOutdated, Check(engine))
+
+	require.NoError(t, Migrate(engine, Latest()))
+	require.NoError(t, Check(engine))
+
+	ok, err := engine.IsTableExist(new(sensors.Order))
+
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// the api broker events
+	ok, err = engine.IsTableExist("eth_sensors_event")
+
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// the models match the latest tables
+	_, err = engine.InsertOne(&sensors.Order{ID: "O_1", TX: "0x1", Status: sensors.StatusRunning})
+
+	require.NoError(t, err)
+
+	// applied migrations are skipped
+	require.NoError(t, Migrate(engine, Latest()))
+
+	require.NoError(t, Migrate(engine, 0))
+	require.Equal(t, sensors.ErrSchemaOutdated, Check(engine))
+
+	current, err := Current(engine)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(0), current)
+
+	ok, err = engine.IsTableExist(new(sensors.Order))
+
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+// legacyOrder the order table synced by orm.Sync before migrations
+type legacyOrder struct {
+	ID      string `xorm:"pk"`
+	ChainID int64  `xorm:"index"`
+	TX      string `xorm:"unique"`
+}
+
+func (table *legacyOrder) TableName() string {
+	return "eth_sensors_order"
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eth-sensors")
+
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	engine, err := xorm.NewEngine("sqlite3", filepath.Join(dir, "sensors.db"))
+
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	require.NoError(t, engine.Sync2(new(legacyOrder)))
+
+	_, err = engine.InsertOne(&legacyOrder{ID: "O_1", ChainID: 1, TX: "0x1"})
+
+	require.NoError(t, err)
+
+	require.NoError(t, Migrate(engine, Latest()))
+
+	// the same tx on other chain is accepted after the legacy tx index dropped
+	_, err = engine.InsertOne(&sensors.Order{ID: "O_2", ChainID: 2, TX: "0x1", Symbol: "ETH", FiatValue: "1"})
+
+	require.NoError(t, err)
+
+	_, err = engine.InsertOne(&sensors.Order{ID: "O_3", ChainID: 2, TX: "0x1"})
+
+	require.Error(t, err)
+
+	// the order table is rebuilt without the reverted columns, the rows are kept
+	require.NoError(t, Migrate(engine, 1))
+
+	count, err := engine.Table("eth_sensors_order").Count()
+
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	_, err = engine.Exec(`SELECT "symbol" FROM "eth_sensors_order"`)
+
+	require.Error(t, err)
+
+	_, err = engine.InsertOne(&orderV1{ID: "O_3", ChainID: 2, TX: "0x1"})
+
+	require.Error(t, err)
+}
@@ -0,0 +1,419 @@
+package db
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-xorm/xorm"
+)
+
+// the table snapshots of the migrations, never change them, add a new migration instead
+
+// watcherV1 the baseline watcher table
+type watcherV1 struct {
+	ID             string `xorm:"pk"`
+	Name           string `xorm:"index"`
+	Key            string `xorm:"unique"`
+	ChainID        int64  `xorm:"unique(address_chain_erc20)"`
+	Address        string `xorm:"unique(address_chain_erc20)"`
+	ERC20          bool   `xorm:"unique(address_chain_erc20)"`
+	Confirmed      int64  `xorm:""`
+	Timeout        int64  `xorm:""`
+	PendingTimeout int64  `xorm:""`
+	ConfirmTimeout int64  `xorm:""`
+}
+
+func (table *watcherV1) TableName() string {
+	return "eth_sensors_watcher"
+}
+
+// orderV1 the baseline order table
+type orderV1 struct {
+	ID             string    `xorm:"pk"`
+	ChainID        int64     `xorm:"unique(chain_tx) index"`
+	TX             string    `xorm:"unique(chain_tx)"`
+	PendingBlock   int64     `xorm:""`
+	CommitBlock    int64     `xorm:""`
+	ConfirmBlock   int64     `xorm:""`
+	Status         string    `xorm:"index"`
+	CreateTime     time.Time `xorm:""`
+	PendingTime    time.Time `xorm:""`
+	CommitTime     time.Time `xorm:""`
+	ConfirmTime    time.Time `xorm:""`
+	From           string    `xorm:"index"`
+	To             string    `xorm:"index"`
+	Value          string    `xorm:"default('0x0')"`
+	Code           string    `xorm:""`
+	GasLimits      string    `xorm:""`
+	GasPrice       string    `xorm:""`
+	ConfirmDepth   int64     `xorm:""`
+	TimeoutDepth   int64     `xorm:""`
+	PendingTimeout int64     `xorm:""`
+	ConfirmTimeout int64     `xorm:""`
+	TimeoutReason  string    `xorm:""`
+	FailureReason  string    `xorm:""`
+	RevertReason   string    `xorm:""`
+	Version        int64     `xorm:""`
+}
+
+func (table *orderV1) TableName() string {
+	return "eth_sensors_order"
+}
+
+// orderHistoryV1 the baseline order history table
+type orderHistoryV1 struct {
+	ID      int64     `xorm:"pk autoincr"`
+	OrderID string    `xorm:"index"`
+	ChainID int64     `xorm:""`
+	TX      string    `xorm:"index"`
+	From    string    `xorm:""`
+	To      string    `xorm:""`
+	Block   int64     `xorm:""`
+	Time    time.Time `xorm:""`
+	Reason  string    `xorm:""`
+}
+
+func (table *orderHistoryV1) TableName() string {
+	return "eth_sensors_order_history"
+}
+
+// leaseV1 the baseline leader lease table
+type leaseV1 struct {
+	Name   string `xorm:"pk"`
+	Holder string `xorm:""`
+	Expire int64  `xorm:""`
+}
+
+func (table *leaseV1) TableName() string {
+	return "eth_sensors_lease"
+}
+
+// erc20V2 the erc20 token registry table
+type erc20V2 struct {
+	ID         int64     `xorm:"pk autoincr"`
+	ChainID    int64     `xorm:"unique(chain_address)"`
+	Address    string    `xorm:"unique(chain_address)"`
+	Name       string    `xorm:""`
+	Symbol     string    `xorm:"index"`
+	Decimals   int64     `xorm:""`
+	CreateTime time.Time `xorm:""`
+}
+
+func (table *erc20V2) TableName() string {
+	return "eth_sensors_erc20"
+}
+
+// orderV2 the order table with the erc20 transfer amount
+type orderV2 struct {
+	ID             string    `xorm:"pk"`
+	ChainID        int64     `xorm:"unique(chain_tx) index"`
+	TX             string    `xorm:"unique(chain_tx)"`
+	PendingBlock   int64     `xorm:""`
+	CommitBlock    int64     `xorm:""`
+	ConfirmBlock   int64     `xorm:""`
+	Status         string    `xorm:"index"`
+	CreateTime     time.Time `xorm:""`
+	PendingTime    time.Time `xorm:""`
+	CommitTime     time.Time `xorm:""`
+	ConfirmTime    time.Time `xorm:""`
+	From           string    `xorm:"index"`
+	To             string    `xorm:"index"`
+	Value          string    `xorm:"default('0x0')"`
+	Code           string    `xorm:""`
+	GasLimits      string    `xorm:""`
+	GasPrice       string    `xorm:""`
+	ConfirmDepth   int64     `xorm:""`
+	TimeoutDepth   int64     `xorm:""`
+	PendingTimeout int64     `xorm:""`
+	ConfirmTimeout int64     `xorm:""`
+	TimeoutReason  string    `xorm:""`
+	FailureReason  string    `xorm:""`
+	RevertReason   string    `xorm:""`
+	Version        int64     `xorm:""`
+	Token          string    `xorm:""`
+	Symbol         string    `xorm:""`
+	Amount         string    `xorm:""`
+}
+
+func (table *orderV2) TableName() string {
+	return "eth_sensors_order"
+}
+
+// orderV3 the order table with the fiat value
+type orderV3 struct {
+	ID             string    `xorm:"pk"`
+	ChainID        int64     `xorm:"unique(chain_tx) index"`
+	TX             string    `xorm:"unique(chain_tx)"`
+	PendingBlock   int64     `xorm:""`
+	CommitBlock    int64     `xorm:""`
+	ConfirmBlock   int64     `xorm:""`
+	Status         string    `xorm:"index"`
+	CreateTime     time.Time `xorm:""`
+	PendingTime    time.Time `xorm:""`
+	CommitTime     time.Time `xorm:""`
+	ConfirmTime    time.Time `xorm:""`
+	From           string    `xorm:"index"`
+	To             string    `xorm:"index"`
+	Value          string    `xorm:"default('0x0')"`
+	Code           string    `xorm:""`
+	GasLimits      string    `xorm:""`
+	GasPrice       string    `xorm:""`
+	ConfirmDepth   int64     `xorm:""`
+	TimeoutDepth   int64     `xorm:""`
+	PendingTimeout int64     `xorm:""`
+	ConfirmTimeout int64     `xorm:""`
+	TimeoutReason  string    `xorm:""`
+	FailureReason  string    `xorm:""`
+	RevertReason   string    `xorm:""`
+	Version        int64     `xorm:""`
+	Token          string    `xorm:""`
+	Symbol         string    `xorm:""`
+	Amount         string    `xorm:""`
+	FiatValue      string    `xorm:""`
+	FiatCurrency   string    `xorm:""`
+}
+
+func (table *orderV3) TableName() string {
+	return "eth_sensors_order"
+}
+
+// orderArchiveV4 the archive table of the terminal orders
+type orderArchiveV4 struct {
+	orderV3     `xorm:"extends"`
+	ArchiveTime time.Time `xorm:""`
+}
+
+func (table *orderArchiveV4) TableName() string {
+	return "eth_sensors_order_archive"
+}
+
+// cursorV5 the last processed block of the chains
+type cursorV5 struct {
+	ChainID    int64     `xorm:"pk"`
+	Block      int64     `xorm:""`
+	Hash       string    `xorm:""`
+	UpdateTime time.Time `xorm:""`
+}
+
+func (table *cursorV5) TableName() string {
+	return "eth_sensors_cursor"
+}
+
+// eventV7 the api order event table, the event id is the stream cursor
+type eventV7 struct {
+	ID         int64     `xorm:"pk autoincr"`
+	Key        string    `xorm:"index"`
+	OrderID    string    `xorm:"index"`
+	Status     string    `xorm:""`
+	Payload    string    `xorm:"text"`
+	CreateTime time.Time `xorm:""`
+}
+
+func (table *eventV7) TableName() string {
+	return "eth_sensors_event"
+}
+
+// orderV8 the order table with the sender nonce
+type orderV8 struct {
+	orderV3 `xorm:"extends"`
+	Nonce   string `xorm:""`
+}
+
+func (table *orderV8) TableName() string {
+	return "eth_sensors_order"
+}
+
+// orderArchiveV8 the archive table with the sender nonce
+type orderArchiveV8 struct {
+	orderV8     `xorm:"extends"`
+	ArchiveTime time.Time `xorm:""`
+}
+
+func (table *orderArchiveV8) TableName() string {
+	return "eth_sensors_order_archive"
+}
+
+// rebuildTable revert the table to the snapshot by copying the snapshot columns into the table created again,
+// sqlite before 3.35 can not drop columns
+func rebuildTable(session *xorm.Session, snapshot interface{ TableName() string }) error {
+	table := snapshot.TableName()
+	rebuild := table + "_rebuild"
+	columns := strings.Join(snapshotColumns(reflect.TypeOf(snapshot).Elem()), ",")
+
+	if err := session.Table(rebuild).CreateTable(snapshot); err != nil {
+		return err
+	}
+
+	if _, err := session.Exec(fmt.Sprintf(`INSERT INTO "%s" (%s) SELECT %s FROM "%s"`, rebuild, columns, columns, table)); err != nil {
+		return err
+	}
+
+	if err := session.DropTable(table); err != nil {
+		return err
+	}
+
+	if _, err := session.Exec(fmt.Sprintf(`ALTER TABLE "%s" RENAME TO "%s"`, rebuild, table)); err != nil {
+		return err
+	}
+
+	if err := session.CreateIndexes(snapshot); err != nil {
+		return err
+	}
+
+	return session.CreateUniques(snapshot)
+}
+
+// snapshotColumns the quoted columns of the snapshot struct named by the default snake mapper
+func snapshotColumns(snapshot reflect.Type) []string {
+	var columns []string
+
+	for i := 0; i < snapshot.NumField(); i++ {
+		field := snapshot.Field(i)
+
+		tag := field.Tag.Get("xorm")
+
+		if tag == "-" {
+			continue
+		}
+
+		if tag == "extends" {
+			columns = append(columns, snapshotColumns(field.Type)...)
+			continue
+		}
+
+		var column []rune
+
+		for j, c := range field.Name {
+			if c >= 'A' && c <= 'Z' {
+				if j > 0 {
+					column = append(column, '_')
+				}
+
+				c += 'a' - 'A'
+			}
+
+			column = append(column, c)
+		}
+
+		columns = append(columns, `"`+string(column)+`"`)
+	}
+
+	return columns
+}
+
+func init() {
+	// the tables created by orm.Sync before migrations, sync keeps the existing databases unchanged
+	Register(&Migration{
+		Version: 1,
+		Name:    "baseline",
+		Up: func(session *xorm.Session) error {
+			return session.Sync2(new(watcherV1), new(orderV1), new(orderHistoryV1), new(leaseV1))
+		},
+		Down: func(session *xorm.Session) error {
+			for _, table := range []interface{}{new(leaseV1), new(orderHistoryV1), new(orderV1), new(watcherV1)} {
+				if err := session.DropTable(table); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	})
+
+	Register(&Migration{
+		Version: 2,
+		Name:    "erc20 registry",
+		Up: func(session *xorm.Session) error {
+			return session.Sync2(new(erc20V2), new(orderV2))
+		},
+		Down: func(session *xorm.Session) error {
+			if err := session.DropTable(new(erc20V2)); err != nil {
+				return err
+			}
+
+			return rebuildTable(session, new(orderV1))
+		},
+	})
+
+	Register(&Migration{
+		Version: 3,
+		Name:    "order fiat value",
+		Up: func(session *xorm.Session) error {
+			return session.Sync2(new(orderV3))
+		},
+		Down: func(session *xorm.Session) error {
+			return rebuildTable(session, new(orderV2))
+		},
+	})
+
+	Register(&Migration{
+		Version: 4,
+		Name:    "order archive",
+		Up: func(session *xorm.Session) error {
+			return session.Sync2(new(orderArchiveV4))
+		},
+		Down: func(session *xorm.Session) error {
+			return session.DropTable(new(orderArchiveV4))
+		},
+	})
+
+	Register(&Migration{
+		Version: 5,
+		Name:    "chain cursor",
+		Up: func(session *xorm.Session) error {
+			return session.Sync2(new(cursorV5))
+		},
+		Down: func(session *xorm.Session) error {
+			return session.DropTable(new(cursorV5))
+		},
+	})
+
+	// the databases synced by orm.Sync before migrations keep the unique indexes of the former models,
+	// which reject the same tx or watched address on other chains
+	Register(&Migration{
+		Version: 6,
+		Name:    "drop pre-migration unique indexes",
+		Up: func(session *xorm.Session) error {
+			for _, index := range []string{"UQE_eth_sensors_order_t_x", "UQE_eth_sensors_watcher_address_erc20"} {
+				if _, err := session.Exec(fmt.Sprintf(`DROP INDEX IF EXISTS "%s"`, index)); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		// the dropped indexes are not part of the migrated schema
+		Down: func(session *xorm.Session) error {
+			return nil
+		},
+	})
+
+	// the api events synced by orm.Sync before migrations
+	Register(&Migration{
+		Version: 7,
+		Name:    "api events",
+		Up: func(session *xorm.Session) error {
+			return session.Sync2(new(eventV7))
+		},
+		Down: func(session *xorm.Session) error {
+			return session.DropTable(new(eventV7))
+		},
+	})
+
+	Register(&Migration{
+		Version: 8,
+		Name:    "order nonce",
+		Up: func(session *xorm.Session) error {
+			return session.Sync2(new(orderV8), new(orderArchiveV8))
+		},
+		Down: func(session *xorm.Session) error {
+			if err := rebuildTable(session, new(orderV3)); err != nil {
+				return err
+			}
+
+			return rebuildTable(session, new(orderArchiveV4))
+		},
+	})
+}
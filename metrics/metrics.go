@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "eth_sensors"
+
+// Result label values
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Sensors metrics, every metric labeled with the reporting component (core, cacher, storage, notifier),
+// chain scoped metrics are labeled with chain id too
+var (
+	ChainHead = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "chain_head_block",
+		Help:      "The chain head block number reported by the eth node",
+	}, []string{"chain"})
+
+	ProcessedBlock = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "processed_block",
+		Help:      "The last block number processed by the sensor",
+	}, []string{"chain"})
+
+	HeadLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "head_lag_blocks",
+		Help:      "Blocks between the chain head and the last processed block",
+	}, []string{"chain"})
+
+	BlocksProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blocks_processed_total",
+		Help:      "Blocks handled by the sensor",
+	}, []string{"component", "chain", "result"})
+
+	BlockDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "block_duration_seconds",
+		Help:      "Time spent on handling one block",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"component", "chain"})
+
+	CachedOrders = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cached_orders",
+		Help:      "Unconfirmed orders held by the order cacher",
+	}, []string{"component", "chain"})
+
+	RPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "Latency of eth node json rpc calls",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"component", "chain", "method", "result"})
+
+	Notifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_total",
+		Help:      "Order notifications sent to watchers",
+	}, []string{"component", "notifier", "result"})
+
+	EndpointHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rpc_endpoint_healthy",
+		Help:      "Whether the rpc pool endpoint is healthy (1) or not (0)",
+	}, []string{"chain", "endpoint"})
+
+	DBDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_duration_seconds",
+		Help:      "Latency of database queries",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"component", "query", "result"})
+
+	ArchivedOrders = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "archived_orders_total",
+		Help:      "Terminal orders moved to the archive by the retention policy",
+	}, []string{"component"})
+
+	ArchiveRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "archive_runs_total",
+		Help:      "Retention archive runs",
+	}, []string{"component", "result"})
+
+	Leader = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "leader",
+		Help:      "Whether the replica holds the leader lease (1) or not (0)",
+	}, []string{"lease"})
+)
+
+// Result get result label value of err
+func Result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+
+	return ResultSuccess
+}
+
+// ObserveRPC record rpc call latency since start
+func ObserveRPC(component string, chain string, method string, start time.Time, err error) {
+	RPCDuration.WithLabelValues(component, chain, method, Result(err)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveDB record database query latency since start
+func ObserveDB(component string, query string, start time.Time, err error) {
+	DBDuration.WithLabelValues(component, query, Result(err)).Observe(time.Since(start).Seconds())
+}
+
+// Handler the prometheus exposition handler
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+type notifierWrapper struct {
+	name     string
+	notifier sensors.Notifier
+}
+
+// Notifier wrap notifier to record notifications result with notifier name
+func Notifier(name string, notifier sensors.Notifier) sensors.Notifier {
+	return &notifierWrapper{
+		name:     name,
+		notifier: notifier,
+	}
+}
+
+func (wrapper *notifierWrapper) Notify(receiver *sensors.Watcher, order *sensors.Order) error {
+	err := wrapper.notifier.Notify(receiver, order)
+
+	Notifications.WithLabelValues("notifier", wrapper.name, Result(err)).Inc()
+
+	return err
+}
+
+func init() {
+	prometheus.MustRegister(
+		ChainHead,
+		ProcessedBlock,
+		HeadLag,
+		BlocksProcessed,
+		BlockDuration,
+		CachedOrders,
+		RPCDuration,
+		Notifications,
+		EndpointHealthy,
+		DBDuration,
+		Leader,
+		ArchivedOrders,
+		ArchiveRuns,
+	)
+}
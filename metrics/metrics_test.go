@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifier(t *testing.T) {
+	var failure error
+
+	notifier := Notifier("test", sensors.NotifierFunc(func(receiver *sensors.Watcher, order *sensors.Order) error {
+		return failure
+	}))
+
+	succeed := Notifications.WithLabelValues("notifier", "test", ResultSuccess)
+	failed := Notifications.WithLabelValues("notifier", "test", ResultFailure)
+
+	require.NoError(t, notifier.Notify(&sensors.Watcher{}, &sensors.Order{}))
+
+	failure = errors.New("notifier down")
+
+	require.Equal(t, failure, notifier.Notify(&sensors.Watcher{}, &sensors.Order{}))
+	require.Equal(t, failure, notifier.Notify(&sensors.Watcher{}, &sensors.Order{}))
+
+	require.Equal(t, float64(1), testutil.ToFloat64(succeed))
+	require.Equal(t, float64(2), testutil.ToFloat64(failed))
+}
+
+func TestHandler(t *testing.T) {
+	ObserveRPC("core", "1", "eth_blockNumber", time.Now(), nil)
+	ObserveDB("core", "get_watchers", time.Now(), errors.New("db down"))
+	ChainHead.WithLabelValues("1").Set(100)
+
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+
+	require.NoError(t, err)
+
+	text := string(body)
+
+	require.Contains(t, text, `eth_sensors_rpc_duration_seconds_count{chain="1",component="core",method="eth_blockNumber",result="success"} 1`)
+	require.Contains(t, text, `eth_sensors_db_duration_seconds_count{component="core",query="get_watchers",result="failure"} 1`)
+	require.Contains(t, text, `eth_sensors_chain_head_block{chain="1"} 100`)
+}
@@ -0,0 +1,116 @@
+package oracle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	config "github.com/dynamicgo/go-config"
+	sensors "github.com/laplacenetwork/eth-sensors"
+)
+
+// Point the asset price since time
+type Point struct {
+	Time  time.Time `json:"time"`
+	Price string    `json:"price"` // decimal price like 250.5
+}
+
+type point struct {
+	time  time.Time
+	price *big.Rat
+}
+
+// staticOracle value the assets by the fixed price points, the price at time is the last point not after it
+type staticOracle struct {
+	currency string
+	prices   map[string][]*point // price points by upper case symbol ordered by time
+}
+
+// New create static price oracle with config:
+// currency: the fiat currency, default USD
+// file: the json file of the price points by symbol, like {"ETH": [{"time": "2019-01-01T00:00:00Z", "price": "250.5"}]}
+// prices: the inline price points by symbol, override the symbols of the file
+func New(config config.Config) (sensors.PriceOracle, error) {
+	prices := make(map[string][]Point)
+
+	if path := config.Get("file").String(""); path != "" {
+		data, err := ioutil.ReadFile(path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(data, &prices); err != nil {
+			return nil, fmt.Errorf("decode price file %s err: %s", path, err)
+		}
+	}
+
+	inline := make(map[string][]Point)
+
+	if err := config.Get("prices").Scan(&inline); err != nil {
+		return nil, err
+	}
+
+	for symbol, points := range inline {
+		prices[symbol] = points
+	}
+
+	return NewStatic(config.Get("currency").String("USD"), prices)
+}
+
+// NewStatic create price oracle of the price points by symbol
+func NewStatic(currency string, prices map[string][]Point) (sensors.PriceOracle, error) {
+	oracle := &staticOracle{
+		currency: currency,
+		prices:   make(map[string][]*point),
+	}
+
+	for symbol, points := range prices {
+		parsed := make([]*point, 0, len(points))
+
+		for _, p := range points {
+			price, ok := new(big.Rat).SetString(p.Price)
+
+			if !ok {
+				return nil, fmt.Errorf("invalid %s price %s", symbol, p.Price)
+			}
+
+			parsed = append(parsed, &point{time: p.Time, price: price})
+		}
+
+		sort.Slice(parsed, func(i, j int) bool {
+			return parsed[i].time.Before(parsed[j].time)
+		})
+
+		oracle.prices[strings.ToUpper(symbol)] = parsed
+	}
+
+	return oracle, nil
+}
+
+func (oracle *staticOracle) Currency() string {
+	return oracle.currency
+}
+
+// Price implement sensors.PriceOracle, the chain is ignored
+func (oracle *staticOracle) Price(chainID int64, symbol string, at time.Time) (*big.Rat, bool, error) {
+	points := oracle.prices[strings.ToUpper(symbol)]
+
+	i := sort.Search(len(points), func(i int) bool {
+		return points[i].time.After(at)
+	})
+
+	if i == 0 {
+		return nil, false, nil
+	}
+
+	return new(big.Rat).Set(points[i-1].price), true, nil
+}
+
+func init() {
+	sensors.RegisterPriceOracle("static", New)
+}
@@ -0,0 +1,45 @@
+package oracle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticPrice(t *testing.T) {
+	start := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	oracle, err := NewStatic("USD", map[string][]Point{
+		"eth": {
+			{Time: start.Add(time.Hour), Price: "300"},
+			{Time: start, Price: "250.5"},
+		},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "USD", oracle.Currency())
+
+	_, ok, err := oracle.Price(1, "ETH", start.Add(-time.Second))
+
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	price, ok, err := oracle.Price(1, "ETH", start.Add(time.Minute))
+
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "250.5", price.FloatString(1))
+
+	price, _, _ = oracle.Price(1, "ETH", start.Add(time.Hour))
+
+	require.Equal(t, "300", price.FloatString(0))
+
+	_, ok, _ = oracle.Price(1, "DAI", start)
+
+	require.False(t, ok)
+
+	_, err = NewStatic("USD", map[string][]Point{"ETH": {{Time: start, Price: "x"}}})
+
+	require.Error(t, err)
+}
@@ -0,0 +1,198 @@
+package prefetch
+
+import (
+	"errors"
+	"sync"
+
+	config "github.com/dynamicgo/go-config"
+	"github.com/openzknetwork/ethgo/rpc"
+)
+
+// Errors
+var (
+	ErrStopped = errors.New("prefetch stopped")
+)
+
+// Fetcher fetch block with full txs by number, size is the memory the block hold (e.g. the raw json length)
+type Fetcher func(number int64) (block *rpc.Block, size int64, err error)
+
+// Handler handle block in order, return error to stop prefetching
+type Handler func(block *rpc.Block) error
+
+// Prefetcher fetch blocks in parallel into an ordered pipeline, blocks are handled sequentially
+type Prefetcher struct {
+	fetch   Fetcher
+	workers int
+	window  int64 // max blocks fetched but not handled
+	memory  int64 // max bytes of blocks fetched but not handled
+}
+
+// New create prefetcher with config:
+// workers: parallel fetching goroutines
+// window: max blocks fetched ahead of the handling block
+// memory: max bytes of the blocks fetched ahead, the next block to handle is always admitted
+func New(config config.Config, fetch Fetcher) *Prefetcher {
+	return NewPrefetcher(
+		fetch,
+		config.Get("workers").Int(8),
+		int64(config.Get("window").Int(64)),
+		int64(config.Get("memory").Int(64*1024*1024)),
+	)
+}
+
+// NewPrefetcher .
+func NewPrefetcher(fetch Fetcher, workers int, window int64, memory int64) *Prefetcher {
+	if workers < 1 {
+		workers = 1
+	}
+
+	if window < int64(workers) {
+		window = int64(workers)
+	}
+
+	return &Prefetcher{
+		fetch:   fetch,
+		workers: workers,
+		window:  window,
+		memory:  memory,
+	}
+}
+
+type result struct {
+	block *rpc.Block
+	size  int64
+	err   error
+}
+
+type task struct {
+	number int64
+	result chan *result
+}
+
+// pipeline the state of one Run
+type pipeline struct {
+	sync.Mutex
+	cond   *sync.Cond
+	used   int64 // bytes held by fetched blocks
+	next   int64 // the next block to handle
+	memory int64
+	done   bool
+}
+
+// admit wait memory for the fetched block, return false if stopped
+func (p *pipeline) admit(number int64, size int64) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	for !p.done && number != p.next && p.used+size > p.memory {
+		p.cond.Wait()
+	}
+
+	if p.done {
+		return false
+	}
+
+	p.used += size
+
+	return true
+}
+
+func (p *pipeline) release(size int64) {
+	p.Lock()
+	p.used -= size
+	p.next++
+	p.Unlock()
+
+	p.cond.Broadcast()
+}
+
+func (p *pipeline) stop() {
+	p.Lock()
+	p.done = true
+	p.Unlock()
+
+	p.cond.Broadcast()
+}
+
+// Run fetch blocks in [from,to] and handle them in order, return the next block to handle
+func (prefetcher *Prefetcher) Run(from int64, to int64, handle Handler) (int64, error) {
+	p := &pipeline{
+		next:   from,
+		memory: prefetcher.memory,
+	}
+
+	p.cond = sync.NewCond(p)
+
+	jobs := make(chan *task)
+	queue := make(chan *task, prefetcher.window)
+	stopped := make(chan struct{})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < prefetcher.workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for job := range jobs {
+				block, size, err := prefetcher.fetch(job.number)
+
+				if err == nil && !p.admit(job.number, size) {
+					err = ErrStopped
+				}
+
+				job.result <- &result{block: block, size: size, err: err}
+			}
+		}()
+	}
+
+	// producer, the queue capacity bound the blocks fetched ahead
+	go func() {
+		defer close(queue)
+		defer close(jobs)
+
+		for number := from; number <= to; number++ {
+			job := &task{number: number, result: make(chan *result, 1)}
+
+			select {
+			case <-stopped:
+				return
+			case queue <- job:
+			}
+
+			select {
+			case <-stopped:
+				return
+			case jobs <- job:
+			}
+		}
+	}()
+
+	defer func() {
+		close(stopped)
+		p.stop()
+		wg.Wait()
+	}()
+
+	for job := range queue {
+		result := <-job.result
+
+		if result.err != nil {
+			return job.number, result.err
+		}
+
+		// a nil block means the node not reach the block yet
+		if result.block == nil {
+			return job.number, nil
+		}
+
+		if err := handle(result.block); err != nil {
+			return job.number, err
+		}
+
+		p.release(result.size)
+	}
+
+	return to + 1, nil
+}
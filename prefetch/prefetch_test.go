@@ -0,0 +1,101 @@
+package prefetch
+
+import (
+	"errors"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openzknetwork/ethgo/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+func blockNumber(block *rpc.Block) int64 {
+	number, _ := strconv.ParseInt(strings.TrimPrefix(block.Number, "0x"), 16, 64)
+
+	return number
+}
+
+func TestOrdered(t *testing.T) {
+	var fetched int64
+
+	prefetcher := NewPrefetcher(func(number int64) (*rpc.Block, int64, error) {
+		time.Sleep(time.Duration(rand.Intn(3)) * time.Millisecond)
+
+		atomic.AddInt64(&fetched, 1)
+
+		return &rpc.Block{Number: "0x" + strconv.FormatInt(number, 16)}, 10, nil
+	}, 8, 16, 40)
+
+	var handled []int64
+
+	next, err := prefetcher.Run(10, 200, func(block *rpc.Block) error {
+		// fetched ahead blocks are bounded by the window
+		require.True(t, atomic.LoadInt64(&fetched)-int64(len(handled)) <= 16+8)
+
+		handled = append(handled, blockNumber(block))
+
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, int64(201), next)
+	require.Len(t, handled, 191)
+
+	for i, number := range handled {
+		require.Equal(t, int64(10+i), number)
+	}
+}
+
+func TestMemoryBound(t *testing.T) {
+	// every block exceed the memory bound, the next block to handle is still admitted
+	prefetcher := NewPrefetcher(func(number int64) (*rpc.Block, int64, error) {
+		return &rpc.Block{Number: "0x" + strconv.FormatInt(number, 16)}, 100, nil
+	}, 8, 64, 50)
+
+	var handled int64
+
+	next, err := prefetcher.Run(0, 100, func(block *rpc.Block) error {
+		require.Equal(t, handled, blockNumber(block))
+
+		handled++
+
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, int64(101), next)
+}
+
+func TestStop(t *testing.T) {
+	errHandle := errors.New("handle")
+
+	prefetcher := NewPrefetcher(func(number int64) (*rpc.Block, int64, error) {
+		if number == 50 {
+			return nil, 0, errors.New("fetch")
+		}
+
+		return &rpc.Block{Number: "0x" + strconv.FormatInt(number, 16)}, 1, nil
+	}, 4, 8, 1024)
+
+	next, err := prefetcher.Run(0, 100, func(block *rpc.Block) error {
+		return nil
+	})
+
+	require.EqualError(t, err, "fetch")
+	require.Equal(t, int64(50), next)
+
+	next, err = prefetcher.Run(0, 100, func(block *rpc.Block) error {
+		if blockNumber(block) == 20 {
+			return errHandle
+		}
+
+		return nil
+	})
+
+	require.Equal(t, errHandle, err)
+	require.Equal(t, int64(20), next)
+}
@@ -0,0 +1,30 @@
+package prefetch
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/laplacenetwork/eth-sensors/metrics"
+	"github.com/laplacenetwork/eth-sensors/rpcpool"
+	"github.com/openzknetwork/ethgo/rpc"
+)
+
+// RPC create fetcher getting blocks by eth_getBlockByNumber over http json rpc,
+// the size is the response json length, component and chain label the rpc metrics
+func RPC(component string, chain string, url string, timeout time.Duration) Fetcher {
+	client := &http.Client{Timeout: timeout}
+
+	return func(number int64) (block *rpc.Block, size int64, err error) {
+		start := time.Now()
+
+		defer func() {
+			metrics.ObserveRPC(component, chain, "eth_getBlockByNumber", start, err)
+		}()
+
+		size, err = rpcpool.Call(context.Background(), client, url, "eth_getBlockByNumber", &block, "0x"+strconv.FormatInt(number, 16), true)
+
+		return block, size, err
+	}
+}
@@ -0,0 +1,434 @@
+package rediscacher
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	config "github.com/dynamicgo/go-config"
+	"github.com/dynamicgo/slf4go"
+	"github.com/go-redis/redis"
+	sensors "github.com/laplacenetwork/eth-sensors"
+)
+
+// mint retry times on concurrent modification
+const mintRetries = 3
+
+// confirmScript take the orders reaching their deadline atomically,
+// so the replicas sharing the cacher never confirm the same order twice
+//
+// KEYS: orders hash, pending deadline zset, running deadline zset, pending block zset, expire time zset
+// ARGV: the confirming block, the confirming block time in milliseconds
+var confirmScript = redis.NewScript(`
+local function take(zset, max)
+	local txs = redis.call('ZRANGEBYSCORE', zset, '-inf', max)
+	local orders = {}
+	for _, tx in ipairs(txs) do
+		local order = redis.call('HGET', KEYS[1], tx)
+		if order then
+			table.insert(orders, order)
+		end
+		redis.call('HDEL', KEYS[1], tx)
+		for i = 2, #KEYS do
+			redis.call('ZREM', KEYS[i], tx)
+		end
+	end
+	return orders
+end
+
+local timeout = take(KEYS[2], ARGV[1])
+local confirmed = take(KEYS[3], ARGV[1])
+
+return {timeout, confirmed, take(KEYS[5], '(' .. ARGV[2])}
+`)
+
+// finalizeScript take the included and safe orders committed at or below the finalized block,
+// and mark the included ones committed at or below the safe block as safe in the safe set,
+// the newly marked orders are returned as is and moved to the safe status by the caller
+//
+// KEYS: orders hash, settling commit block zset, safe set
+// ARGV: the safe block, the finalized block
+var finalizeScript = redis.NewScript(`
+local finalized = {}
+for _, tx in ipairs(redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[2])) do
+	local order = redis.call('HGET', KEYS[1], tx)
+	if order then
+		table.insert(finalized, order)
+	end
+	redis.call('HDEL', KEYS[1], tx)
+	redis.call('ZREM', KEYS[2], tx)
+	redis.call('SREM', KEYS[3], tx)
+end
+
+local safe = {}
+for _, tx in ipairs(redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])) do
+	if redis.call('SADD', KEYS[3], tx) == 1 then
+		local order = redis.call('HGET', KEYS[1], tx)
+		if order then
+			table.insert(safe, order)
+		end
+	end
+end
+
+return {safe, finalized}
+`)
+
+// cacherImpl cache orders in redis, the orders are stored in hash indexed by tx,
+// pending and running orders are indexed by deadline block with sorted sets,
+// included and safe orders are indexed by commit block and the safe ones are marked in a set,
+// the orders with timeout duration are indexed by expire time,
+// the deadline is computed with the depth recorded on order or the cacher default
+type cacherImpl struct {
+	slf4go.Logger
+	client         *redis.Client
+	prefix         string
+	scope          string
+	confirmBlocks  int64
+	timeoutBlocks  int64
+	pendingTimeout time.Duration
+	confirmTimeout time.Duration
+}
+
+// New create redis order cacher with config:
+// redis: addr/password/db of the redis server
+// prefix: the key prefix, default eth-sensors
+// order: confirmed/timeout blocks and pendingtimeout/confirmtimeout durations, the same as the memory cacher
+func New(config config.Config) (sensors.OrderCacher, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Get("redis", "addr").String("localhost:6379"),
+		Password: config.Get("redis", "password").String(""),
+		DB:       config.Get("redis", "db").Int(0),
+	})
+
+	if err := client.Ping().Err(); err != nil {
+		return nil, err
+	}
+
+	return NewCacher(
+		client,
+		config.Get("prefix").String("eth-sensors"),
+		int64(config.Get("order", "confirmed").Int(1)),
+		int64(config.Get("order", "timeout").Int(60)),
+		config.Get("order", "pendingtimeout").Duration(0),
+		config.Get("order", "confirmtimeout").Duration(0),
+	), nil
+}
+
+// NewCacher .
+func NewCacher(client *redis.Client, prefix string, confirmBlocks int64, timeoutBlocks int64, pendingTimeout time.Duration, confirmTimeout time.Duration) sensors.SharedOrderCacher {
+	return &cacherImpl{
+		Logger:         slf4go.Get("redis-cacher"),
+		client:         client,
+		prefix:         prefix,
+		scope:          prefix,
+		confirmBlocks:  confirmBlocks,
+		timeoutBlocks:  timeoutBlocks,
+		pendingTimeout: pendingTimeout,
+		confirmTimeout: confirmTimeout,
+	}
+}
+
+func (cacher *cacherImpl) key(name string) string {
+	return cacher.scope + ":" + name
+}
+
+func (cacher *cacherImpl) Bind(chainID int64) {
+	cacher.scope = cacher.prefix + ":" + strconv.FormatInt(chainID, 10)
+}
+
+func (cacher *cacherImpl) Loaded() bool {
+	n, err := cacher.client.Exists(cacher.key("loaded")).Result()
+
+	if err != nil {
+		cacher.ErrorF("check loaded err: %s", err)
+		return false
+	}
+
+	return n > 0
+}
+
+func (cacher *cacherImpl) Cache(orders []*sensors.Order) {
+	_, err := cacher.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		for _, order := range orders {
+			if err := cacher.put(pipe, order); err != nil {
+				return err
+			}
+		}
+
+		pipe.Set(cacher.key("loaded"), "1", 0)
+
+		return nil
+	})
+
+	if err != nil {
+		cacher.ErrorF("cache orders(%d) err: %s", len(orders), err)
+	}
+}
+
+// put save order and index it by status
+func (cacher *cacherImpl) put(pipe redis.Pipeliner, order *sensors.Order) error {
+	data, err := json.Marshal(order)
+
+	if err != nil {
+		return err
+	}
+
+	pipe.HSet(cacher.key("orders"), order.TX, data)
+
+	pipe.ZRem(cacher.key("pending"), order.TX)
+	pipe.ZRem(cacher.key("running"), order.TX)
+	pipe.ZRem(cacher.key("pendings"), order.TX)
+	pipe.ZRem(cacher.key("settling"), order.TX)
+	pipe.ZRem(cacher.key("expires"), order.TX)
+	pipe.SRem(cacher.key("safes"), order.TX)
+
+	switch order.Status {
+	case sensors.StatusPending:
+		deadline := order.PendingBlock + depth(order.TimeoutDepth, cacher.timeoutBlocks) + 1
+		pipe.ZAdd(cacher.key("pending"), redis.Z{Score: float64(deadline), Member: order.TX})
+		pipe.ZAdd(cacher.key("pendings"), redis.Z{Score: float64(order.PendingBlock), Member: order.TX})
+	case sensors.StatusRunning:
+		deadline := order.CommitBlock + depth(order.ConfirmDepth, cacher.confirmBlocks) + 1
+		pipe.ZAdd(cacher.key("running"), redis.Z{Score: float64(deadline), Member: order.TX})
+	case sensors.StatusIncluded:
+		pipe.ZAdd(cacher.key("settling"), redis.Z{Score: float64(order.CommitBlock), Member: order.TX})
+	case sensors.StatusSafe:
+		pipe.ZAdd(cacher.key("settling"), redis.Z{Score: float64(order.CommitBlock), Member: order.TX})
+		pipe.SAdd(cacher.key("safes"), order.TX)
+	}
+
+	if expire, ok := cacher.expire(order); ok {
+		pipe.ZAdd(cacher.key("expires"), redis.Z{Score: float64(millis(expire)), Member: order.TX})
+	}
+
+	return nil
+}
+
+// expire the block time after which the order timeout, the same as the memory cacher
+func (cacher *cacherImpl) expire(order *sensors.Order) (time.Time, bool) {
+	var start time.Time
+	var timeout time.Duration
+
+	switch order.Status {
+	case sensors.StatusPending:
+		start, timeout = order.PendingTime, duration(order.PendingTimeout, cacher.pendingTimeout)
+	case sensors.StatusRunning:
+		start, timeout = order.CommitTime, duration(order.ConfirmTimeout, cacher.confirmTimeout)
+	}
+
+	if timeout <= 0 {
+		return time.Time{}, false
+	}
+
+	return start.Add(timeout), true
+}
+
+func duration(seconds int64, defaultDuration time.Duration) time.Duration {
+	if seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	return defaultDuration
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func depth(order int64, defaultDepth int64) int64 {
+	if order > 0 {
+		return order
+	}
+
+	return defaultDepth
+}
+
+func (cacher *cacherImpl) get(client redis.Cmdable, tx string) (*sensors.Order, error) {
+	data, err := client.HGet(cacher.key("orders"), tx).Bytes()
+
+	if err == redis.Nil {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	order := new(sensors.Order)
+
+	if err := json.Unmarshal(data, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (cacher *cacherImpl) Mint(tx string, block int64, time time.Time) (*sensors.Order, *sensors.OrderHistory, bool) {
+	var order *sensors.Order
+	var history *sensors.OrderHistory
+
+	mint := func(client *redis.Tx) error {
+		var err error
+
+		order, err = cacher.get(client, tx)
+
+		if err != nil || order == nil {
+			return err
+		}
+
+		if history, err = order.Transit(sensors.StatusRunning, block, time, ""); err != nil {
+			order = nil
+			return nil
+		}
+
+		order.CommitBlock = block
+		order.CommitTime = time
+
+		_, err = client.TxPipelined(func(pipe redis.Pipeliner) error {
+			return cacher.put(pipe, order)
+		})
+
+		return err
+	}
+
+	for i := 0; i < mintRetries; i++ {
+		err := cacher.client.Watch(mint, cacher.key("orders"))
+
+		if err == redis.TxFailedErr {
+			continue
+		}
+
+		if err != nil {
+			cacher.ErrorF("mint order %s err: %s", tx, err)
+			return nil, nil, false
+		}
+
+		return order, history, order != nil
+	}
+
+	cacher.ErrorF("mint order %s err: retry %d times", tx, mintRetries)
+
+	return nil, nil, false
+}
+
+func (cacher *cacherImpl) Confirm(block int64, time time.Time) (timeout []*sensors.Order, confirmed []*sensors.Order) {
+	result, err := confirmScript.Run(
+		cacher.client,
+		[]string{cacher.key("orders"), cacher.key("pending"), cacher.key("running"), cacher.key("pendings"), cacher.key("expires")},
+		block, millis(time),
+	).Result()
+
+	if err != nil {
+		cacher.ErrorF("confirm block %d err: %s", block, err)
+		return nil, nil
+	}
+
+	groups, _ := result.([]interface{})
+
+	if len(groups) != 3 {
+		cacher.ErrorF("confirm block %d unexpect script result %v", block, result)
+		return nil, nil
+	}
+
+	for _, order := range cacher.decode(groups[0]) {
+		order.TimeoutReason = sensors.TimeoutBlock
+		timeout = append(timeout, order)
+	}
+
+	for _, order := range cacher.decode(groups[2]) {
+		order.TimeoutReason = sensors.TimeoutTime
+		timeout = append(timeout, order)
+	}
+
+	return timeout, cacher.decode(groups[1])
+}
+
+func (cacher *cacherImpl) Finalize(safe int64, finalized int64) (safeOrders []*sensors.Order, finalizedOrders []*sensors.Order) {
+	result, err := finalizeScript.Run(
+		cacher.client,
+		[]string{cacher.key("orders"), cacher.key("settling"), cacher.key("safes")},
+		safe, finalized,
+	).Result()
+
+	if err != nil {
+		cacher.ErrorF("finalize block %d/%d err: %s", safe, finalized, err)
+		return nil, nil
+	}
+
+	groups, _ := result.([]interface{})
+
+	if len(groups) != 2 {
+		cacher.ErrorF("finalize block %d/%d unexpect script result %v", safe, finalized, result)
+		return nil, nil
+	}
+
+	return cacher.decode(groups[0]), cacher.decode(groups[1])
+}
+
+func (cacher *cacherImpl) decode(result interface{}) []*sensors.Order {
+	values, _ := result.([]interface{})
+
+	var orders []*sensors.Order
+
+	for _, value := range values {
+		data, _ := value.(string)
+
+		order := new(sensors.Order)
+
+		if err := json.Unmarshal([]byte(data), order); err != nil {
+			cacher.ErrorF("decode order %s err: %s", data, err)
+			continue
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders
+}
+
+func (cacher *cacherImpl) Pending() (*sensors.Order, bool) {
+	txs, err := cacher.client.ZRevRange(cacher.key("pendings"), 0, 0).Result()
+
+	if err != nil {
+		cacher.ErrorF("get pending order err: %s", err)
+		return nil, false
+	}
+
+	if len(txs) == 0 {
+		return nil, false
+	}
+
+	order, err := cacher.get(cacher.client, txs[0])
+
+	if err != nil {
+		cacher.ErrorF("get pending order %s err: %s", txs[0], err)
+		return nil, false
+	}
+
+	return order, order != nil
+}
+
+func (cacher *cacherImpl) Pend(order *sensors.Order) {
+	_, err := cacher.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		return cacher.put(pipe, order)
+	})
+
+	if err != nil {
+		cacher.ErrorF("pend order %s err: %s", order.TX, err)
+	}
+}
+
+func (cacher *cacherImpl) Size() int {
+	n, err := cacher.client.HLen(cacher.key("orders")).Result()
+
+	if err != nil {
+		cacher.ErrorF("get cached orders size err: %s", err)
+		return 0
+	}
+
+	return int(n)
+}
+
+func init() {
+	sensors.RegisterCacher("redis-cacher", New)
+}
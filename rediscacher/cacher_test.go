@@ -0,0 +1,279 @@
+package rediscacher
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCacher(t *testing.T, server *miniredis.Miniredis, chainID int64) sensors.SharedOrderCacher {
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+	cacher := NewCacher(client, "test", 2, 5, 0, 0)
+
+	cacher.Bind(chainID)
+
+	return cacher
+}
+
+func TestConfirm(t *testing.T) {
+	server, err := miniredis.Run()
+
+	require.NoError(t, err)
+
+	defer server.Close()
+
+	cacher := newTestCacher(t, server, 1)
+
+	require.False(t, cacher.Loaded())
+
+	cacher.Cache([]*sensors.Order{
+		{ID: "O_1", TX: "0x1", Status: sensors.StatusPending, PendingBlock: 10},
+		{ID: "O_2", TX: "0x2", Status: sensors.StatusRunning, PendingBlock: 10, CommitBlock: 10},
+	})
+
+	require.True(t, cacher.Loaded())
+
+	cacher.Pend(&sensors.Order{ID: "O_3", TX: "0x3", Status: sensors.StatusPending, PendingBlock: 11})
+
+	require.Equal(t, 3, cacher.Size())
+
+	order, ok := cacher.Pending()
+
+	require.True(t, ok)
+	require.Equal(t, "O_3", order.ID)
+
+	commitTime := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	order, history, ok := cacher.Mint("0x3", 12, commitTime)
+
+	require.True(t, ok)
+	require.Equal(t, sensors.StatusRunning, order.Status)
+	require.Equal(t, sensors.StatusPending, history.From)
+	require.Equal(t, sensors.StatusRunning, history.To)
+	require.Equal(t, int64(12), history.Block)
+
+	_, _, ok = cacher.Mint("0x4", 12, commitTime)
+
+	require.False(t, ok)
+
+	timeout, confirmed := cacher.Confirm(12, commitTime)
+
+	require.Empty(t, timeout)
+	require.Len(t, confirmed, 0)
+
+	timeout, confirmed = cacher.Confirm(13, commitTime)
+
+	require.Empty(t, timeout)
+	require.Len(t, confirmed, 1)
+	require.Equal(t, "O_2", confirmed[0].ID)
+
+	timeout, confirmed = cacher.Confirm(16, commitTime)
+
+	require.Len(t, timeout, 1)
+	require.Equal(t, "O_1", timeout[0].ID)
+	require.Len(t, confirmed, 1)
+	require.Equal(t, "O_3", confirmed[0].ID)
+	require.Equal(t, int64(12), confirmed[0].CommitBlock)
+	require.True(t, commitTime.Equal(confirmed[0].CommitTime))
+
+	require.Equal(t, 0, cacher.Size())
+
+	// orders of other chains are isolated
+	require.False(t, newTestCacher(t, server, 137).Loaded())
+}
+
+func TestConfirmReplicas(t *testing.T) {
+	server, err := miniredis.Run()
+
+	require.NoError(t, err)
+
+	defer server.Close()
+
+	replicas := []sensors.OrderCacher{
+		newTestCacher(t, server, 1),
+		newTestCacher(t, server, 1),
+	}
+
+	for i := 0; i < 100; i++ {
+		replicas[i%2].Pend(&sensors.Order{
+			ID:          fmt.Sprintf("O_%d", i),
+			TX:          fmt.Sprintf("0x%x", i),
+			Status:      sensors.StatusRunning,
+			CommitBlock: int64(i),
+		})
+	}
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+
+	confirmed := make(map[string]int)
+
+	for _, replica := range replicas {
+		wg.Add(1)
+
+		go func(replica sensors.OrderCacher) {
+			defer wg.Done()
+
+			for block := int64(0); block < 110; block++ {
+				_, orders := replica.Confirm(block, time.Now())
+
+				lock.Lock()
+
+				for _, order := range orders {
+					confirmed[order.ID]++
+				}
+
+				lock.Unlock()
+			}
+		}(replica)
+	}
+
+	wg.Wait()
+
+	require.Len(t, confirmed, 100)
+
+	for id, n := range confirmed {
+		require.Equal(t, 1, n, id)
+	}
+}
+
+func TestOrderDepth(t *testing.T) {
+	server, err := miniredis.Run()
+
+	require.NoError(t, err)
+
+	defer server.Close()
+
+	cacher := newTestCacher(t, server, 1)
+
+	cacher.Cache([]*sensors.Order{
+		{ID: "O_1", TX: "0x1", Status: sensors.StatusRunning, CommitBlock: 10},
+		{ID: "O_2", TX: "0x2", Status: sensors.StatusRunning, CommitBlock: 10, ConfirmDepth: 12},
+		{ID: "O_3", TX: "0x3", Status: sensors.StatusPending, PendingBlock: 10, TimeoutDepth: 1},
+	})
+
+	timeout, confirmed := cacher.Confirm(13, time.Now())
+
+	require.Len(t, timeout, 1)
+	require.Equal(t, "O_3", timeout[0].ID)
+	require.Len(t, confirmed, 1)
+	require.Equal(t, "O_1", confirmed[0].ID)
+
+	_, confirmed = cacher.Confirm(22, time.Now())
+
+	require.Empty(t, confirmed)
+
+	_, confirmed = cacher.Confirm(23, time.Now())
+
+	require.Len(t, confirmed, 1)
+	require.Equal(t, int64(12), confirmed[0].ConfirmDepth)
+}
+
+func TestFinalize(t *testing.T) {
+	server, err := miniredis.Run()
+
+	require.NoError(t, err)
+
+	defer server.Close()
+
+	cacher := newTestCacher(t, server, 1)
+
+	cacher.Cache([]*sensors.Order{
+		{ID: "O_1", TX: "0x1", Status: sensors.StatusIncluded, CommitBlock: 10},
+		{ID: "O_2", TX: "0x2", Status: sensors.StatusIncluded, CommitBlock: 12},
+		{ID: "O_3", TX: "0x3", Status: sensors.StatusSafe, CommitBlock: 11},
+	})
+
+	// included orders never expire by depth
+	_, confirmed := cacher.Confirm(100, time.Now())
+
+	require.Empty(t, confirmed)
+
+	safe, finalized := cacher.Finalize(12, 10)
+
+	require.Len(t, safe, 1)
+	require.Equal(t, "O_2", safe[0].ID)
+	require.Equal(t, sensors.StatusIncluded, safe[0].Status)
+	require.Len(t, finalized, 1)
+	require.Equal(t, "O_1", finalized[0].ID)
+
+	// the marked order is taken once
+	safe, finalized = cacher.Finalize(12, 10)
+
+	require.Empty(t, safe)
+	require.Empty(t, finalized)
+
+	// the order recached after the failed settlement is marked again
+	cacher.Cache([]*sensors.Order{{ID: "O_2", TX: "0x2", Status: sensors.StatusIncluded, CommitBlock: 12}})
+
+	safe, _ = cacher.Finalize(12, 10)
+
+	require.Len(t, safe, 1)
+	require.Equal(t, "O_2", safe[0].ID)
+
+	// the settled safe order is cached with its transited status
+	safe[0].Status = sensors.StatusSafe
+
+	cacher.Pend(safe[0])
+
+	safe, finalized = cacher.Finalize(12, 10)
+
+	require.Empty(t, safe)
+	require.Empty(t, finalized)
+
+	safe, finalized = cacher.Finalize(12, 12)
+
+	require.Empty(t, safe)
+	require.Len(t, finalized, 2)
+	require.Equal(t, "O_3", finalized[0].ID)
+	require.Equal(t, sensors.StatusSafe, finalized[1].Status)
+
+	require.Equal(t, 0, cacher.Size())
+}
+
+func TestTimeout(t *testing.T) {
+	server, err := miniredis.Run()
+
+	require.NoError(t, err)
+
+	defer server.Close()
+
+	cacher := NewCacher(redis.NewClient(&redis.Options{Addr: server.Addr()}), "test", 2, 5, time.Minute, 0)
+
+	start := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	cacher.Cache([]*sensors.Order{
+		{ID: "O_1", TX: "0x1", Status: sensors.StatusPending, PendingBlock: 10, PendingTime: start},
+		{ID: "O_2", TX: "0x2", Status: sensors.StatusRunning, CommitBlock: 10, CommitTime: start, ConfirmTimeout: 30},
+		{ID: "O_3", TX: "0x3", Status: sensors.StatusPending, PendingBlock: 9, PendingTime: start.Add(time.Hour)},
+	})
+
+	timeout, confirmed := cacher.Confirm(11, start.Add(time.Minute))
+
+	require.Len(t, timeout, 1)
+	require.Equal(t, "O_2", timeout[0].ID)
+	require.Equal(t, sensors.TimeoutTime, timeout[0].TimeoutReason)
+	require.Empty(t, confirmed)
+
+	timeout, _ = cacher.Confirm(15, start.Add(time.Minute+time.Second))
+
+	require.Len(t, timeout, 2)
+
+	reasons := map[string]sensors.TimeoutReason{}
+
+	for _, order := range timeout {
+		reasons[order.ID] = order.TimeoutReason
+	}
+
+	require.Equal(t, sensors.TimeoutBlock, reasons["O_3"])
+	require.Equal(t, sensors.TimeoutTime, reasons["O_1"])
+
+	require.Equal(t, 0, cacher.Size())
+}
@@ -0,0 +1,92 @@
+package rpcpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// Error the json rpc error member, the reverted eth_call carry the revert data
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("json rpc error %d: %s", err.Code, err.Message)
+}
+
+// Call send the json rpc request to url by client and decode the result, the size is the response json length,
+// the json rpc error response is returned as *Error, the client timeout limits the stalled node
+func Call(ctx context.Context, client *http.Client, url string, method string, result interface{}, params ...interface{}) (int64, error) {
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := post(ctx, client, url, body)
+
+	if err != nil {
+		return 0, err
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+
+	if err := json.Unmarshal(data, &response); err != nil {
+		return 0, fmt.Errorf("decode %s response err: %s", method, err)
+	}
+
+	if response.Error != nil {
+		return 0, response.Error
+	}
+
+	return int64(len(data)), json.Unmarshal(response.Result, result)
+}
+
+func post(ctx context.Context, client *http.Client, url string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http status %d: %s", resp.StatusCode, string(data))
+	}
+
+	return data, nil
+}
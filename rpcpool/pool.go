@@ -0,0 +1,377 @@
+package rpcpool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/dynamicgo/go-config"
+	"github.com/dynamicgo/slf4go"
+	"github.com/laplacenetwork/eth-sensors/metrics"
+	"golang.org/x/time/rate"
+)
+
+// Errors
+var (
+	ErrNoEndpoint = errors.New("no available rpc endpoint")
+)
+
+// latency ewma weight of the newest sample
+const latencyWeight = 0.2
+
+type endpoint struct {
+	url      string
+	limiter  *rate.Limiter
+	latency  float64 // ewma latency in seconds
+	head     int64
+	healthy  bool
+	failures int // continuous failures
+}
+
+func (ep *endpoint) score() float64 {
+	return ep.latency * float64(1+ep.failures)
+}
+
+// Pool the json rpc endpoint pool, which forward requests to the best endpoint and fail over to others on error.
+// The pool serves a local json rpc endpoint, so the components which only accept node url can share it.
+type Pool struct {
+	sync.RWMutex
+	slf4go.Logger
+	chain     string
+	endpoints []*endpoint
+	client    *http.Client
+	maxLag    int64
+	interval  time.Duration
+	listener  net.Listener
+	server    *http.Server
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// New create endpoint pool with config:
+// endpoints: the rpc endpoint urls
+// rps/burst: per endpoint rate limit, zero rps means no limit
+// maxlag: endpoint with head lag behind the highest head more than maxlag blocks is unhealthy
+// interval: health check interval
+// timeout: per request timeout
+func New(chain string, config config.Config) (*Pool, error) {
+	return newPool(
+		chain,
+		config.Get("endpoints").StringSlice(nil),
+		config.Get("rps").Float64(0),
+		config.Get("burst").Int(10),
+		int64(config.Get("maxlag").Int(5)),
+		config.Get("interval").Duration(5*time.Second),
+		config.Get("timeout").Duration(10*time.Second),
+	)
+}
+
+func newPool(chain string, urls []string, rps float64, burst int, maxLag int64, interval time.Duration, timeout time.Duration) (*Pool, error) {
+	if len(urls) == 0 {
+		return nil, ErrNoEndpoint
+	}
+
+	pool := &Pool{
+		Logger:   slf4go.Get("rpcpool-" + chain),
+		chain:    chain,
+		client:   &http.Client{Timeout: timeout},
+		maxLag:   maxLag,
+		interval: interval,
+		closed:   make(chan struct{}),
+	}
+
+	for _, url := range urls {
+		limit := rate.Inf
+
+		if rps > 0 {
+			limit = rate.Limit(rps)
+		}
+
+		pool.endpoints = append(pool.endpoints, &endpoint{
+			url:     url,
+			limiter: rate.NewLimiter(limit, burst),
+			healthy: true,
+		})
+	}
+
+	return pool, nil
+}
+
+// Start check endpoints health and serve the local json rpc endpoint
+func (pool *Pool) Start() error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		return err
+	}
+
+	pool.listener = listener
+	pool.server = &http.Server{Handler: pool}
+
+	pool.check()
+
+	go pool.server.Serve(listener)
+	go pool.checkLoop()
+
+	return nil
+}
+
+// URL the local json rpc endpoint url
+func (pool *Pool) URL() string {
+	return "http://" + pool.listener.Addr().String()
+}
+
+// Close stop the pool
+func (pool *Pool) Close() {
+	pool.closeOnce.Do(func() {
+		close(pool.closed)
+
+		if pool.server != nil {
+			pool.server.Close()
+		}
+	})
+}
+
+// ServeHTTP forward json rpc request
+func (pool *Pool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := pool.Do(r.Context(), body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// Do send json rpc request body to the best endpoint, try next endpoint on failure,
+// the json rpc error response of the last endpoint is returned as is if all endpoints failed
+func (pool *Pool) Do(ctx context.Context, body []byte) ([]byte, error) {
+	method := requestMethod(body)
+
+	var lastErr error = ErrNoEndpoint
+	var lastResp []byte
+
+	for _, ep := range pool.candidates() {
+		// wait for the endpoint rate limit instead of dropping the request
+		if err := ep.limiter.Wait(ctx); err != nil {
+			lastErr = err
+
+			if ctx.Err() != nil {
+				break
+			}
+
+			continue
+		}
+
+		start := time.Now()
+
+		resp, err := post(ctx, pool.client, ep.url, body)
+
+		if err == nil {
+			if rpcErr := responseError(resp); rpcErr != nil && rpcErr.node() {
+				err = rpcErr
+				lastResp = resp
+			}
+		}
+
+		metrics.ObserveRPC("rpcpool", pool.chain, method, start, err)
+
+		pool.record(ep, time.Since(start), err)
+
+		if err == nil {
+			return resp, nil
+		}
+
+		pool.WarnF("call %s on endpoint %s err: %s", method, ep.url, err)
+
+		lastErr = err
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if lastResp != nil && ctx.Err() == nil {
+		return lastResp, nil
+	}
+
+	return nil, lastErr
+}
+
+// candidates the endpoints ordered by health and score
+func (pool *Pool) candidates() []*endpoint {
+	pool.RLock()
+	defer pool.RUnlock()
+
+	endpoints := append([]*endpoint(nil), pool.endpoints...)
+
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		if endpoints[i].healthy != endpoints[j].healthy {
+			return endpoints[i].healthy
+		}
+
+		return endpoints[i].score() < endpoints[j].score()
+	})
+
+	return endpoints
+}
+
+func (pool *Pool) record(ep *endpoint, latency time.Duration, err error) {
+	pool.Lock()
+	defer pool.Unlock()
+
+	if ep.latency == 0 {
+		ep.latency = latency.Seconds()
+	} else {
+		ep.latency = ep.latency*(1-latencyWeight) + latency.Seconds()*latencyWeight
+	}
+
+	if err != nil {
+		ep.failures++
+
+		// the health check marks it healthy again
+		if ep.healthy {
+			pool.WarnF("endpoint %s unhealthy err: %s", ep.url, err)
+			ep.healthy = false
+			metrics.EndpointHealthy.WithLabelValues(pool.chain, ep.url).Set(0)
+		}
+	} else {
+		ep.failures = 0
+	}
+}
+
+func (pool *Pool) checkLoop() {
+	ticker := time.NewTicker(pool.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pool.closed:
+			return
+		case <-ticker.C:
+			pool.check()
+		}
+	}
+}
+
+// check query every endpoint head, endpoints failed or lag behind the highest head are marked unhealthy
+func (pool *Pool) check() {
+	heads := make([]int64, len(pool.endpoints))
+	errs := make([]error, len(pool.endpoints))
+
+	var wg sync.WaitGroup
+
+	for i, ep := range pool.endpoints {
+		wg.Add(1)
+
+		go func(i int, ep *endpoint) {
+			defer wg.Done()
+
+			start := time.Now()
+
+			heads[i], errs[i] = pool.blockNumber(ep.url)
+
+			pool.record(ep, time.Since(start), errs[i])
+		}(i, ep)
+	}
+
+	wg.Wait()
+
+	pool.Lock()
+	defer pool.Unlock()
+
+	var highest int64
+
+	for i, ep := range pool.endpoints {
+		if errs[i] == nil {
+			ep.head = heads[i]
+
+			if ep.head > highest {
+				highest = ep.head
+			}
+		}
+	}
+
+	for i, ep := range pool.endpoints {
+		healthy := errs[i] == nil && highest-ep.head <= pool.maxLag
+
+		if healthy != ep.healthy {
+			pool.InfoF("endpoint %s healthy %v -> %v, head %d highest %d err %v", ep.url, ep.healthy, healthy, ep.head, highest, errs[i])
+		}
+
+		ep.healthy = healthy
+
+		value := 0.0
+
+		if healthy {
+			value = 1
+		}
+
+		metrics.EndpointHealthy.WithLabelValues(pool.chain, ep.url).Set(value)
+	}
+}
+
+func (pool *Pool) blockNumber(url string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pool.client.Timeout)
+	defer cancel()
+
+	var result string
+
+	if _, err := Call(ctx, pool.client, url, "eth_blockNumber", &result); err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(strings.TrimPrefix(result, "0x"), 16, 64)
+}
+
+// node check if the error is caused by the endpoint, the invalid requests and the reverted calls fail on every endpoint
+func (err *Error) node() bool {
+	switch err.Code {
+	case 3, -32700, -32600, -32602:
+		return false
+	}
+
+	return !strings.Contains(strings.ToLower(err.Message), "revert")
+}
+
+// responseError get the error member of the json rpc response, nil for the batch response
+func responseError(data []byte) *Error {
+	var resp struct {
+		Error *Error `json:"error"`
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil
+	}
+
+	return resp.Error
+}
+
+func requestMethod(body []byte) string {
+	var req struct {
+		Method string `json:"method"`
+	}
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		return "batch"
+	}
+
+	return req.Method
+}
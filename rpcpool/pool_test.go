@@ -0,0 +1,152 @@
+package rpcpool
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNode the json rpc endpoint answering eth_blockNumber and the configured method result or error
+type fakeNode struct {
+	sync.Mutex
+	*httptest.Server
+	result interface{}
+	err    map[string]interface{}
+	calls  int
+}
+
+func newFakeNode(result interface{}) *fakeNode {
+	node := &fakeNode{result: result}
+
+	node.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     int    `json:"id"`
+			Method string `json:"method"`
+		}
+
+		json.NewDecoder(r.Body).Decode(&req)
+
+		resp := map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+		}
+
+		if req.Method == "eth_blockNumber" {
+			resp["result"] = "0x10"
+		} else {
+			node.Lock()
+			node.calls++
+
+			if node.err != nil {
+				resp["error"] = node.err
+			} else {
+				resp["result"] = node.result
+			}
+
+			node.Unlock()
+		}
+
+		json.NewEncoder(w).Encode(resp)
+	}))
+
+	return node
+}
+
+func (node *fakeNode) fail(code int, message string) {
+	node.Lock()
+	defer node.Unlock()
+
+	node.err = map[string]interface{}{"code": code, "message": message}
+}
+
+func (node *fakeNode) count() int {
+	node.Lock()
+	defer node.Unlock()
+
+	return node.calls
+}
+
+const request = `{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByNumber","params":["0x10",true]}`
+
+func TestFailover(t *testing.T) {
+	first := newFakeNode("first")
+	defer first.Close()
+
+	second := newFakeNode("second")
+	defer second.Close()
+
+	pool, err := newPool("1", []string{first.URL, second.URL}, 0, 10, 5, time.Hour, time.Second)
+
+	require.NoError(t, err)
+
+	defer pool.Close()
+
+	// the endpoint returning json rpc error is failed over and marked unhealthy
+	first.fail(-32000, "header not found")
+
+	resp, err := pool.Do(context.Background(), []byte(request))
+
+	require.NoError(t, err)
+	require.Contains(t, string(resp), "second")
+	require.Equal(t, 1, first.count())
+
+	resp, err = pool.Do(context.Background(), []byte(request))
+
+	require.NoError(t, err)
+	require.Contains(t, string(resp), "second")
+	require.Equal(t, 1, first.count())
+
+	// the reverted call fails on every endpoint, returned as is
+	second.fail(3, "execution reverted")
+
+	resp, err = pool.Do(context.Background(), []byte(request))
+
+	require.NoError(t, err)
+	require.Contains(t, string(resp), "execution reverted")
+	require.Equal(t, 1, first.count())
+
+	// the stopped endpoint is failed over
+	second.Close()
+
+	resp, err = pool.Do(context.Background(), []byte(request))
+
+	require.NoError(t, err)
+	require.Contains(t, string(resp), "header not found")
+	require.Equal(t, 2, first.count())
+}
+
+func TestRateLimit(t *testing.T) {
+	node := newFakeNode("ok")
+	defer node.Close()
+
+	pool, err := newPool("1", []string{node.URL}, 20, 1, 5, time.Hour, time.Second)
+
+	require.NoError(t, err)
+
+	start := time.Now()
+
+	// the saturated endpoint delays the requests instead of dropping them
+	for i := 0; i < 3; i++ {
+		resp, err := pool.Do(context.Background(), []byte(request))
+
+		require.NoError(t, err)
+		require.Contains(t, string(resp), "ok")
+	}
+
+	require.True(t, time.Since(start) >= 80*time.Millisecond)
+	require.Equal(t, 3, node.count())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = pool.Do(ctx, []byte(request))
+
+	require.Error(t, err)
+	require.Equal(t, 3, node.count())
+}
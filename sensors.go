@@ -3,6 +3,7 @@ package sensors
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
@@ -10,12 +11,21 @@ import (
 
 	config "github.com/dynamicgo/go-config"
 	"github.com/dynamicgo/orm"
+	"github.com/go-xorm/xorm"
+	"github.com/openzknetwork/ethgo/rpc"
 )
 
 // Errors
 var (
-	ErrVersion       = errors.New("order version error")
-	ErrWatcherExists = errors.New("watcher exists")
+	ErrVersion        = errors.New("order version error")
+	ErrWatcherExists  = errors.New("watcher exists")
+	ErrOrderNotFound  = errors.New("order not found")
+	ErrOrderRunning   = errors.New("order is waiting for confirm")
+	ErrOrderArchived  = errors.New("order is archived")
+	ErrNotSupport     = errors.New("operation not support")
+	ErrChainNotFound  = errors.New("chain not found")
+	ErrNotLeader      = errors.New("sensor replica is not the leader")
+	ErrSchemaOutdated = errors.New("database schema is not at the latest migration version")
 )
 
 // Status .
@@ -29,26 +39,69 @@ var (
 	StatusSucceed  = Status("SUCCEED")
 	StatusFailed   = Status("FAILED")
 	StatusCanceled = Status("CANCELED")
+	// finality tag confirmation stages, see the chain config "confirm.tag"
+	StatusIncluded  = Status("INCLUDED")  // the tx block is included in the chain
+	StatusSafe      = Status("SAFE")      // the tx block is at or below the safe block
+	StatusFinalized = Status("FINALIZED") // the tx block is at or below the finalized block
+)
+
+// TimeoutReason the reason of the order timeout
+type TimeoutReason string
+
+// TimeoutReason .
+var (
+	TimeoutBlock = TimeoutReason("block") // the timeout blocks passed
+	TimeoutTime  = TimeoutReason("time")  // the timeout duration passed by block time
+)
+
+// FailureReason the reason of the failed order
+type FailureReason string
+
+// FailureReason .
+var (
+	FailureReverted = FailureReason("REVERTED")   // the tx reverted, see the order revert reason
+	FailureOutOfGas = FailureReason("OUT_OF_GAS") // the tx used up the gas limit
+	FailureTimeout  = FailureReason("TIMEOUT")    // the order timeout, see the order timeout reason
+	FailureDropped  = FailureReason("DROPPED")    // the tx receipt disappeared and the tx is unknown
+	FailureReplaced = FailureReason("REPLACED")   // another tx of the sender with the same nonce was mined
+	FailureIllegal  = FailureReason("ILLEGAL")    // the order reached an illegal status transition, see the order history
 )
 
 // Order the eth tx order
 type Order struct {
-	ID           string    `xorm:"pk"`
-	TX           string    `xorm:"unique"`
-	PendingBlock int64     `xorm:""`
-	CommitBlock  int64     `xorm:""`
-	ConfirmBlock int64     `xorm:""`
-	Status       Status    `xorm:"index"`
-	CreateTime   time.Time `xorm:"created"`
-	PendingTime  time.Time `xorm:""`
-	CommitTime   time.Time `xorm:""`
-	ConfirmTime  time.Time `xorm:""`
-	From         string    `xorm:"index"`
-	To           string    `xorm:"index"`
-	Value        string    `xorm:"default('0x0')"`
-	Code         string    `xorm:""`
-	GasLimits    string    `xorm:""`
-	GasPrice     string    `xorm:""`
+	ID             string        `xorm:"pk"`
+	ChainID        int64         `xorm:"unique(chain_tx) index"`
+	TX             string        `xorm:"unique(chain_tx)"`
+	PendingBlock   int64         `xorm:""`
+	CommitBlock    int64         `xorm:""`
+	ConfirmBlock   int64         `xorm:""`
+	Status         Status        `xorm:"index"`
+	CreateTime     time.Time     `xorm:""`
+	PendingTime    time.Time     `xorm:""`
+	CommitTime     time.Time     `xorm:""`
+	ConfirmTime    time.Time     `xorm:""`
+	From           string        `xorm:"index"`
+	To             string        `xorm:"index"`
+	Value          string        `xorm:"default('0x0')"`
+	Code           string        `xorm:""`
+	GasLimits      string        `xorm:""`
+	GasPrice       string        `xorm:""`
+	ConfirmDepth   int64         `xorm:""` // confirmation blocks chosen by policy or the cacher default, 0 on the legacy orders means the cacher default
+	TimeoutDepth   int64         `xorm:""` // pending timeout blocks chosen by policy or the cacher default, 0 on the legacy orders means the cacher default
+	PendingTimeout int64         `xorm:""` // pending timeout seconds chosen by policy, 0 means the cacher default
+	ConfirmTimeout int64         `xorm:""` // confirmation timeout seconds since commit chosen by policy, 0 means the cacher default
+	TimeoutReason  TimeoutReason `xorm:""` // block or time if the order timeout
+	FailureReason  FailureReason `xorm:""` // set if the order failed
+	RevertReason   string        `xorm:""` // the revert string, panic code or custom error data of the reverted tx
+	Version        int64         `xorm:""` // increased by every update, the update with stale version fail with ErrVersion
+	Token          string        `xorm:""` // the token contract of the erc20 transfer, empty for the native coin transfer
+	Symbol         string        `xorm:""` // the transferred token or native coin symbol
+	Amount         string        `xorm:""` // the transferred amount in decimal, empty if the token decimals unknown
+	FiatValue      string        `xorm:""` // the amount value in decimal at the commit block time, empty if no price
+	FiatCurrency   string        `xorm:""` // the fiat currency of the value like USD
+	Nonce          string        `xorm:""` // the sender nonce of the tx, tell the replaced tx after the node forgot it
+
+	Archived bool `xorm:"-"` // true if found in the archive, the archived orders are read only
 }
 
 // TableName .
@@ -56,13 +109,42 @@ func (table *Order) TableName() string {
 	return "eth_sensors_order"
 }
 
+// Unconfirmed check if the order is still watched by the cacher,
+// safe orders wait for the finalized block until the confirm block recorded
+func (table *Order) Unconfirmed() bool {
+	switch table.Status {
+	case StatusPending, StatusRunning, StatusIncluded:
+		return true
+	case StatusSafe:
+		return table.ConfirmBlock < 0
+	}
+
+	return false
+}
+
+// ArchivedOrder the terminal order moved out of the order table by the retention policy
+type ArchivedOrder struct {
+	Order       `xorm:"extends"`
+	ArchiveTime time.Time `xorm:""`
+}
+
+// TableName .
+func (table *ArchivedOrder) TableName() string {
+	return "eth_sensors_order_archive"
+}
+
 // Watcher the eth event watcher managed by sensors
 type Watcher struct {
-	ID      string `xorm:"pk"`                    // watcher id
-	Name    string `xorm:"index"`                 // watcher name
-	Key     string `xorm:"unique"`                // watcher unique key provider by notifier
-	Address string `xorm:"unique(address_erc20)"` // watched address
-	ERC20   bool   `xorm:"unique(address_erc20)"` // true if the target address is a erc20 contract address
+	ID             string `xorm:"pk"`                          // watcher id
+	Name           string `xorm:"index"`                       // watcher name
+	Key            string `xorm:"unique"`                      // watcher unique key provider by notifier
+	ChainID        int64  `xorm:"unique(address_chain_erc20)"` // the chain id of watched address
+	Address        string `xorm:"unique(address_chain_erc20)"` // watched address
+	ERC20          bool   `xorm:"unique(address_chain_erc20)"` // true if the target address is a erc20 contract address
+	Confirmed      int64  `xorm:""`                            // confirmation blocks required by watcher, 0 means no requirement
+	Timeout        int64  `xorm:""`                            // pending timeout blocks required by watcher, 0 means no requirement
+	PendingTimeout int64  `xorm:""`                            // pending timeout seconds required by watcher, 0 means no requirement
+	ConfirmTimeout int64  `xorm:""`                            // confirmation timeout seconds required by watcher, 0 means no requirement
 }
 
 // TableName .
@@ -70,6 +152,47 @@ func (table *Watcher) TableName() string {
 	return "eth_sensors_watcher"
 }
 
+// ERC20 the erc20 token metadata registry, filled by the watched contracts and the seen transfers
+type ERC20 struct {
+	ID         int64     `xorm:"pk autoincr"`
+	ChainID    int64     `xorm:"unique(chain_address)"`
+	Address    string    `xorm:"unique(chain_address)"`
+	Name       string    `xorm:""`
+	Symbol     string    `xorm:"index"`
+	Decimals   int64     `xorm:""`
+	CreateTime time.Time `xorm:""`
+}
+
+// TableName .
+func (table *ERC20) TableName() string {
+	return "eth_sensors_erc20"
+}
+
+// Lease the leader lease shared by the sensor replicas through the database, only the holder runs the block sources
+type Lease struct {
+	Name   string `xorm:"pk"` // election name
+	Holder string `xorm:""`   // the replica holding the lease
+	Expire int64  `xorm:""`   // lease expire unix milliseconds, other replicas take over after it
+}
+
+// TableName .
+func (table *Lease) TableName() string {
+	return "eth_sensors_lease"
+}
+
+// Cursor the last processed block of one chain, the block sources resume from it after restart
+type Cursor struct {
+	ChainID    int64     `xorm:"pk"`
+	Block      int64     `xorm:""`
+	Hash       string    `xorm:""`
+	UpdateTime time.Time `xorm:""`
+}
+
+// TableName .
+func (table *Cursor) TableName() string {
+	return "eth_sensors_cursor"
+}
+
 // Sensor The eth tx detect service
 type Sensor interface {
 	// create a new watcher with config
@@ -78,6 +201,40 @@ type Sensor interface {
 	Delete(key string) (err error)
 	// list the register watcher
 	List(page orm.Page) ([]*Watcher, int64, error)
+	// get order by tx hash
+	Order(tx string) (*Order, error)
+	// force re-check order receipt and notify watchers if the status changed
+	Recheck(tx string) (*Order, error)
+	// get order status transitions by tx hash
+	History(tx string) ([]*OrderHistory, error)
+	// rewind the chain block indexer cursor to block
+	Rewind(chainID int64, block int64) error
+	// replay notifications of chain orders committed in block range [from,to]
+	Replay(chainID int64, from int64, to int64) (int, error)
+	// get sensor health and sync status of every chain
+	Status() ([]*SyncStatus, error)
+}
+
+// ClosableSensor optional Sensor interface implemented by the sensors stopped on the process exit
+type ClosableSensor interface {
+	Sensor
+	Close() error // stop the block sources and release the leader lease, the closed sensor only serves the read apis
+}
+
+// SyncStatus the sensor health and sync status of one chain
+type SyncStatus struct {
+	ChainID         int64     // chain id
+	Block           int64     // last processed block number
+	BlockHash       string    // last processed block hash
+	BlockTime       time.Time // last processed block timestamp
+	Head            int64     // chain head block number reported by eth node
+	Lag             int64     // blocks behind chain head
+	LagSeconds      int64     // seconds between now and the last processed block timestamp
+	LastProcessed   time.Time // the time the last block was processed successfully
+	Unconfirmed     int       // unconfirmed orders number
+	NotifierFailing bool      // true if the last notification failed
+	NotifierError   string    // the last notification error
+	Leader          bool      // true if the replica runs the block sources
 }
 
 // Notifier the eth tx event notifier
@@ -96,18 +253,64 @@ func (fn NotifierFunc) Notify(receiver *Watcher, order *Order) error {
 
 // OrderStorage .
 type OrderStorage interface {
-	Save(order *Order) error
-	Update(order *Order) error
+	Save(order *Order, histories ...*OrderHistory) error   // save new order with its transitions
+	Update(order *Order, histories ...*OrderHistory) error // update order with its transitions atomically, ErrVersion if the order version is stale
+	History(orderID string) ([]*OrderHistory, error)       // the order transitions in order
 	Unconfirmed() ([]*Order, error)
+	Find(chainID int64, tx string) (*Order, error)               // find chain order by tx hash including the archive, chain 0 matches any chain, return nil if not found
+	Range(chainID int64, from int64, to int64) ([]*Order, error) // chain orders committed in block range [from,to] including the archive
+	Archive(before time.Time, limit int) (int, error)            // move at most limit terminal orders created before time to the archive
 }
 
 // OrderCacher .
 type OrderCacher interface {
 	Cache([]*Order)                                                             // load unconfirmed  orders
-	Mint(tx string, block int64, time time.Time) (*Order, bool)                 // mint cached order with tx string
 	Confirm(block int64, time time.Time) (timeout []*Order, confirmed []*Order) // confirm orders
 	Pending() (*Order, bool)                                                    // pending order number
 	Pend(order *Order)
+	Size() int // cached orders number
+	// move the cached order with tx to running by the state machine, return the transition to save with the order
+	Mint(tx string, block int64, time time.Time) (*Order, *OrderHistory, bool)
+	// take included or safe orders committed at or below the finalized block, and mark included orders
+	// committed at or below the safe block as safe, the safe orders are returned with the included status
+	// for the caller to transit, the orders never expire by Confirm
+	Finalize(safe int64, finalized int64) (safeOrders []*Order, finalizedOrders []*Order)
+}
+
+// SharedOrderCacher optional OrderCacher interface implemented by the cachers shared between sensor replicas and kept over restart
+type SharedOrderCacher interface {
+	OrderCacher
+	Bind(chainID int64) // scope the cached orders to chain, called before any other method
+	Loaded() bool       // true if the unconfirmed orders are already cached, skip reloading them from storage
+}
+
+// SourceChain the chain fed by block source
+type SourceChain interface {
+	ID() int64                     // chain id
+	Ethnode() string               // chain json rpc url
+	Handle(block *rpc.Block) error // handle block with full txs, blocks must be handled in order
+	Contracts() ([]string, error)  // watched erc20 contract addresses
+	// the last processed block persisted by the chain, block is -1 if no block processed yet
+	Cursor() (block int64, hash string, err error)
+}
+
+// BlockSource the block stream feeding one chain
+type BlockSource interface {
+	Run() error               // feed blocks until the source drained or failed
+	Rewind(block int64) error // feed blocks from block again, return ErrNotSupport if not support
+}
+
+// ClosableBlockSource optional BlockSource interface implemented by the sources stopped after the replica lost the lease
+type ClosableBlockSource interface {
+	BlockSource
+	Close() error // stop feeding blocks and make Run return, the closed source is not run again
+}
+
+// PriceOracle the fiat price source valuing the order amounts
+type PriceOracle interface {
+	Currency() string // the fiat currency like USD
+	// the fiat price of one asset unit at time, ok is false if the price unknown
+	Price(chainID int64, symbol string, at time.Time) (price *big.Rat, ok bool, err error)
 }
 
 // NotifierF notifier factory
@@ -119,8 +322,14 @@ type CoreF func(config config.Config, plugin *Plugin) (Sensor, error)
 // OrderCacherF OrderCacher factory
 type OrderCacherF func(config config.Config) (OrderCacher, error)
 
-// OrderStorageF OrderStorage factory
-type OrderStorageF func(config config.Config) (OrderStorage, error)
+// OrderStorageF OrderStorage factory, clock is the sensor clock stamping the orders
+type OrderStorageF func(config config.Config, clock Clock) (OrderStorage, error)
+
+// PriceOracleF PriceOracle factory, config is the "oracle" section
+type PriceOracleF func(config config.Config) (PriceOracle, error)
+
+// BlockSourceF BlockSource factory, config is the chain config
+type BlockSourceF func(config config.Config, chain SourceChain) (BlockSource, error)
 
 // Plugin sensors plugin object
 type Plugin struct {
@@ -129,6 +338,11 @@ type Plugin struct {
 	sensorsCreator      CoreF
 	OrderStorageCreator OrderStorageF
 	OrderCacherCreator  OrderCacherF
+	PriceOracleCreator  PriceOracleF            // optional, create the oracle if "oracle" configured
+	BlockSourceCreators map[string]BlockSourceF // block sources indexed by name, chose by chain config "source"
+	IDGenerator         IDGenerator             // optional, default snowflake generator
+	Clock               Clock                   // optional, default system clock
+	DB                  *xorm.Engine            // optional, the database shared with the caller, default opened by the "database" config
 }
 
 var plugin *Plugin
@@ -136,7 +350,8 @@ var once sync.Once
 
 func initPlugin() {
 	plugin = &Plugin{
-		Logger: slf4go.Get("eth-detechor-register"),
+		Logger:              slf4go.Get("eth-detechor-register"),
+		BlockSourceCreators: make(map[string]BlockSourceF),
 	}
 }
 
@@ -176,16 +391,41 @@ func RegisterStorage(name string, cacherF OrderStorageF) {
 	plugin.OrderStorageCreator = cacherF
 }
 
+// RegisterPriceOracle .
+func RegisterPriceOracle(name string, oracleF PriceOracleF) {
+	once.Do(initPlugin)
+
+	plugin.DebugF("create price oracle: %s", name)
+
+	plugin.PriceOracleCreator = oracleF
+}
+
+// RegisterBlockSource .
+func RegisterBlockSource(name string, sourceF BlockSourceF) {
+	once.Do(initPlugin)
+
+	plugin.DebugF("create block source: %s", name)
+
+	plugin.BlockSourceCreators[name] = sourceF
+}
+
 // New create sensors
 func New(config config.Config, options ...Option) (Sensor, error) {
 
-	p := &Plugin{}
+	p := &Plugin{
+		BlockSourceCreators: make(map[string]BlockSourceF),
+	}
 
 	if plugin != nil {
+		for name, sourceF := range plugin.BlockSourceCreators {
+			p.BlockSourceCreators[name] = sourceF
+		}
+
 		p.NotifierCreator = plugin.NotifierCreator
 		p.OrderCacherCreator = plugin.OrderCacherCreator
 		p.sensorsCreator = plugin.sensorsCreator
 		p.OrderStorageCreator = plugin.OrderStorageCreator
+		p.PriceOracleCreator = plugin.PriceOracleCreator
 	}
 
 	for _, option := range options {
@@ -208,6 +448,10 @@ func New(config config.Config, options ...Option) (Sensor, error) {
 		return nil, fmt.Errorf("expect import order cacher implement")
 	}
 
+	if len(p.BlockSourceCreators) == 0 {
+		return nil, fmt.Errorf("expect import block source implement")
+	}
+
 	return p.sensorsCreator(config, p)
 }
 
@@ -220,3 +464,52 @@ func WithNotifier(notifier NotifierF) Option {
 		plugin.NotifierCreator = notifier
 	}
 }
+
+// WithStorage .
+func WithStorage(storage OrderStorageF) Option {
+	return func(plugin *Plugin) {
+		plugin.OrderStorageCreator = storage
+	}
+}
+
+// WithCacher .
+func WithCacher(cacher OrderCacherF) Option {
+	return func(plugin *Plugin) {
+		plugin.OrderCacherCreator = cacher
+	}
+}
+
+// WithPriceOracle .
+func WithPriceOracle(oracle PriceOracleF) Option {
+	return func(plugin *Plugin) {
+		plugin.PriceOracleCreator = oracle
+	}
+}
+
+// WithBlockSource .
+func WithBlockSource(name string, source BlockSourceF) Option {
+	return func(plugin *Plugin) {
+		plugin.BlockSourceCreators[name] = source
+	}
+}
+
+// WithIDGenerator .
+func WithIDGenerator(generator IDGenerator) Option {
+	return func(plugin *Plugin) {
+		plugin.IDGenerator = generator
+	}
+}
+
+// WithClock .
+func WithClock(clock Clock) Option {
+	return func(plugin *Plugin) {
+		plugin.Clock = clock
+	}
+}
+
+// WithDB .
+func WithDB(db *xorm.Engine) Option {
+	return func(plugin *Plugin) {
+		plugin.DB = db
+	}
+}
@@ -0,0 +1,55 @@
+package sensorstest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Clock the manual clock
+type Clock struct {
+	sync.Mutex
+	now time.Time
+}
+
+// NewClock create manual clock start at now
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now implement sensors.Clock
+func (clock *Clock) Now() time.Time {
+	clock.Lock()
+	defer clock.Unlock()
+
+	return clock.now
+}
+
+// Advance move the clock forward
+func (clock *Clock) Advance(d time.Duration) {
+	clock.Lock()
+	defer clock.Unlock()
+
+	clock.now = clock.now.Add(d)
+}
+
+// Sequence the sequential id generator, generate ids like O_1, O_2
+type Sequence struct {
+	sync.Mutex
+	seq int64
+}
+
+// NewSequence create sequential id generator
+func NewSequence() *Sequence {
+	return &Sequence{}
+}
+
+// Generate implement sensors.IDGenerator
+func (sequence *Sequence) Generate(prefix string) string {
+	sequence.Lock()
+	defer sequence.Unlock()
+
+	sequence.seq++
+
+	return fmt.Sprintf("%s%d", prefix, sequence.seq)
+}
@@ -0,0 +1,70 @@
+package sensorstest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	config "github.com/dynamicgo/go-config"
+	"github.com/dynamicgo/go-config/source/file"
+)
+
+// Config create sensor config which connect to the fake node and use sqlite database source,
+// values override the top level config entries
+func Config(node *Node, database string, values map[string]interface{}) (config.Config, error) {
+	db := map[string]interface{}{
+		"driver": "sqlite3",
+		"source": database,
+	}
+
+	data := map[string]interface{}{
+		"ethnode":  node.URL(),
+		"database": db,
+		"storage": map[string]interface{}{
+			"database": db,
+		},
+		"cacher": map[string]interface{}{
+			"order": map[string]interface{}{
+				"confirmed": 1,
+				"timeout":   10,
+			},
+		},
+		"notifier": map[string]interface{}{},
+		"head": map[string]interface{}{
+			"interval": "100ms",
+		},
+	}
+
+	for key, value := range values {
+		data[key] = value
+	}
+
+	buff, err := json.Marshal(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := ioutil.TempFile("", "sensorstest-*.json")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(buff); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	f.Close()
+
+	conf := config.NewConfig()
+
+	if err := conf.Load(file.NewSource(file.WithPath(f.Name()))); err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
@@ -0,0 +1,626 @@
+package sensorstest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Transaction the scripted transaction
+type Transaction struct {
+	Hash     string `json:"hash"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Value    string `json:"value"`
+	Gas      string `json:"gas"`
+	GasPrice string `json:"gasPrice"`
+	Input    string `json:"input"`
+	Nonce    string `json:"nonce"`
+}
+
+// Log the scripted receipt log
+type Log struct {
+	Address         string   `json:"address"`
+	Topics          []string `json:"topics"`
+	Data            string   `json:"data"`
+	BlockNumber     string   `json:"blockNumber"`
+	BlockHash       string   `json:"blockHash"`
+	TransactionHash string   `json:"transactionHash"`
+	LogIndex        string   `json:"logIndex"`
+}
+
+// Receipt the scripted transaction receipt
+type Receipt struct {
+	TransactionHash string `json:"transactionHash"`
+	BlockNumber     string `json:"blockNumber"`
+	BlockHash       string `json:"blockHash"`
+	Status          string `json:"status"`
+	GasUsed         string `json:"gasUsed"`
+	Logs            []*Log `json:"logs"`
+}
+
+// Block the scripted block
+type Block struct {
+	Number       int64
+	Hash         string
+	ParentHash   string
+	Timestamp    int64
+	Transactions []*Transaction
+}
+
+// Node the fake eth json rpc node, the chain, receipts and errors are scripted from go code
+type Node struct {
+	sync.Mutex
+	server    *httptest.Server
+	blocks    []*Block
+	receipts  map[string]*Receipt
+	calls     map[string]string // eth_call results indexed by to+data
+	failures  map[string][]string
+	counts    map[string]int
+	genesis   int64
+	blockTime int64
+	forks     int
+	subs      []*subscription
+	safe      int64 // the safe tag block, -1 means the head
+	finalized int64 // the finalized tag block, -1 means the head
+}
+
+// NewNode create and start fake node with the genesis block
+func NewNode() *Node {
+	node := &Node{
+		receipts:  make(map[string]*Receipt),
+		calls:     make(map[string]string),
+		failures:  make(map[string][]string),
+		counts:    make(map[string]int),
+		genesis:   time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC).Unix(),
+		blockTime: 15,
+		safe:      -1,
+		finalized: -1,
+	}
+
+	node.blocks = append(node.blocks, &Block{
+		Number:    0,
+		Hash:      hash("block", 0, 0),
+		Timestamp: node.genesis,
+	})
+
+	node.server = httptest.NewServer(http.HandlerFunc(node.serve))
+
+	return node
+}
+
+// URL the node json rpc http endpoint
+func (node *Node) URL() string {
+	return node.server.URL
+}
+
+// Close stop the node
+func (node *Node) Close() {
+	node.server.Close()
+}
+
+// Head get the chain head block
+func (node *Node) Head() *Block {
+	node.Lock()
+	defer node.Unlock()
+
+	return node.blocks[len(node.blocks)-1]
+}
+
+// Mine append a new block with txs to the chain, every tx get a success receipt
+func (node *Node) Mine(txs ...*Transaction) *Block {
+	node.Lock()
+	defer node.Unlock()
+
+	return node.mine(txs)
+}
+
+// MineEmpty append n empty blocks to the chain
+func (node *Node) MineEmpty(n int) {
+	node.Lock()
+	defer node.Unlock()
+
+	for i := 0; i < n; i++ {
+		node.mine(nil)
+	}
+}
+
+func (node *Node) mine(txs []*Transaction) *Block {
+	parent := node.blocks[len(node.blocks)-1]
+
+	block := &Block{
+		Number:       parent.Number + 1,
+		Hash:         hash("block", parent.Number+1, node.forks),
+		ParentHash:   parent.Hash,
+		Timestamp:    parent.Timestamp + node.blockTime,
+		Transactions: txs,
+	}
+
+	node.blocks = append(node.blocks, block)
+
+	defer node.announce(block)
+
+	for _, tx := range txs {
+		if receipt, ok := node.receipts[tx.Hash]; ok && receipt.BlockHash == "" {
+			// keep the scripted receipt status
+			receipt.BlockNumber = hexInt(block.Number)
+			receipt.BlockHash = block.Hash
+			continue
+		}
+
+		node.receipts[tx.Hash] = &Receipt{
+			TransactionHash: tx.Hash,
+			BlockNumber:     hexInt(block.Number),
+			BlockHash:       block.Hash,
+			Status:          "0x1",
+			GasUsed:         "0x5208",
+		}
+	}
+
+	return block
+}
+
+// WriteBlocks write the chain blocks with full txs as json lines, the format replayed by the file block source
+func (node *Node) WriteBlocks(w io.Writer) error {
+	node.Lock()
+	defer node.Unlock()
+
+	encoder := json.NewEncoder(w)
+
+	for _, block := range node.blocks {
+		if err := encoder.Encode(node.encodeBlock(block, true)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reorg drop the last depth blocks and mine the replacement blocks, dropped txs lose their receipts
+func (node *Node) Reorg(depth int, blocks ...[]*Transaction) {
+	node.Lock()
+	defer node.Unlock()
+
+	if depth >= len(node.blocks) {
+		depth = len(node.blocks) - 1
+	}
+
+	for _, block := range node.blocks[len(node.blocks)-depth:] {
+		for _, tx := range block.Transactions {
+			delete(node.receipts, tx.Hash)
+		}
+	}
+
+	node.blocks = node.blocks[:len(node.blocks)-depth]
+	node.forks++
+
+	for _, txs := range blocks {
+		node.mine(txs)
+	}
+}
+
+// SetReceipt override tx receipt, the receipt will be attached to the block which include the tx
+func (node *Node) SetReceipt(receipt *Receipt) {
+	node.Lock()
+	defer node.Unlock()
+
+	node.receipts[receipt.TransactionHash] = receipt
+}
+
+// SetFinality set the block numbers returned for the safe and finalized tags, -1 means the head
+func (node *Node) SetFinality(safe int64, finalized int64) {
+	node.Lock()
+	defer node.Unlock()
+
+	node.safe = safe
+	node.finalized = finalized
+}
+
+// Revert set the tx receipt status to failed
+func (node *Node) Revert(tx string) {
+	node.Lock()
+	defer node.Unlock()
+
+	if receipt, ok := node.receipts[tx]; ok {
+		receipt.Status = "0x0"
+		receipt.GasUsed = "0x5000"
+		return
+	}
+
+	node.receipts[tx] = &Receipt{
+		TransactionHash: tx,
+		Status:          "0x0",
+		GasUsed:         "0x5000",
+	}
+}
+
+// SetCall set eth_call result of contract call
+func (node *Node) SetCall(to string, data string, result string) {
+	node.Lock()
+	defer node.Unlock()
+
+	node.calls[strings.ToLower(to)+strings.ToLower(data)] = result
+}
+
+// SetToken set the erc20 metadata calls of the token contract
+func (node *Node) SetToken(token string, name string, symbol string, decimals int64) {
+	node.SetCall(token, "0x06fdde03", encodeString(name))
+	node.SetCall(token, "0x95d89b41", encodeString(symbol))
+	node.SetCall(token, "0x313ce567", "0x"+pad(strconv.FormatInt(decimals, 16)))
+}
+
+// Fail let the next n calls of method return json rpc error with message
+func (node *Node) Fail(method string, n int, message string) {
+	node.Lock()
+	defer node.Unlock()
+
+	for i := 0; i < n; i++ {
+		node.failures[method] = append(node.failures[method], message)
+	}
+}
+
+// Calls get the called times of method
+func (node *Node) Calls(method string) int {
+	node.Lock()
+	defer node.Unlock()
+
+	return node.counts[method]
+}
+
+type request struct {
+	ID      json.RawMessage   `json:"id"`
+	JSONRPC string            `json:"jsonrpc"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+type response struct {
+	ID      json.RawMessage `json:"id"`
+	JSONRPC string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+func (node *Node) serve(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		node.serveWS(w, r)
+		return
+	}
+
+	var req request
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := node.dispatch(&req)
+
+	resp := &response{
+		ID:      req.ID,
+		JSONRPC: "2.0",
+		Result:  result,
+		Error:   err,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (node *Node) dispatch(req *request) (interface{}, *rpcError) {
+	node.Lock()
+	defer node.Unlock()
+
+	node.counts[req.Method]++
+
+	if failures := node.failures[req.Method]; len(failures) > 0 {
+		node.failures[req.Method] = failures[1:]
+		return nil, &rpcError{Code: -32000, Message: failures[0]}
+	}
+
+	switch req.Method {
+	case "eth_blockNumber":
+		return hexInt(node.blocks[len(node.blocks)-1].Number), nil
+	case "eth_chainId", "net_version":
+		return "0x1", nil
+	case "eth_getBlockByNumber":
+		var tag string
+		var full bool
+
+		if err := params(req, &tag, &full); err != nil {
+			return nil, err
+		}
+
+		block := node.blockByTag(tag)
+
+		if block == nil {
+			return nil, nil
+		}
+
+		return node.encodeBlock(block, full), nil
+	case "eth_getBlockByHash":
+		var blockHash string
+		var full bool
+
+		if err := params(req, &blockHash, &full); err != nil {
+			return nil, err
+		}
+
+		for _, block := range node.blocks {
+			if block.Hash == blockHash {
+				return node.encodeBlock(block, full), nil
+			}
+		}
+
+		return nil, nil
+	case "eth_getTransactionByHash":
+		var txHash string
+
+		if err := params(req, &txHash); err != nil {
+			return nil, err
+		}
+
+		for _, block := range node.blocks {
+			for _, tx := range block.Transactions {
+				if tx.Hash == txHash {
+					return tx, nil
+				}
+			}
+		}
+
+		return nil, nil
+	case "eth_getTransactionCount":
+		var address string
+
+		if err := params(req, &address); err != nil {
+			return nil, err
+		}
+
+		// the next nonce of the mined txs
+		count := int64(0)
+
+		for _, block := range node.blocks {
+			for _, tx := range block.Transactions {
+				nonce, _ := strconv.ParseInt(strings.TrimPrefix(tx.Nonce, "0x"), 16, 64)
+
+				if strings.EqualFold(tx.From, address) && nonce >= count {
+					count = nonce + 1
+				}
+			}
+		}
+
+		return hexInt(count), nil
+	case "eth_getTransactionReceipt":
+		var txHash string
+
+		if err := params(req, &txHash); err != nil {
+			return nil, err
+		}
+
+		receipt, ok := node.receipts[txHash]
+
+		if !ok || receipt.BlockHash == "" {
+			return nil, nil
+		}
+
+		return receipt, nil
+	case "eth_call":
+		var call struct {
+			To   string `json:"to"`
+			Data string `json:"data"`
+		}
+
+		if err := params(req, &call); err != nil {
+			return nil, err
+		}
+
+		result, ok := node.calls[strings.ToLower(call.To)+strings.ToLower(call.Data)]
+
+		if !ok {
+			return "0x", nil
+		}
+
+		if strings.HasPrefix(result, "revert:") {
+			return nil, &rpcError{Code: 3, Message: "execution reverted", Data: strings.TrimPrefix(result, "revert:")}
+		}
+
+		return result, nil
+	case "eth_getLogs":
+		var filter struct {
+			FromBlock string   `json:"fromBlock"`
+			ToBlock   string   `json:"toBlock"`
+			Address   []string `json:"address"`
+		}
+
+		if err := params(req, &filter); err != nil {
+			return nil, err
+		}
+
+		return node.logs(filter.FromBlock, filter.ToBlock, filter.Address), nil
+	default:
+		return nil, &rpcError{Code: -32601, Message: fmt.Sprintf("method %s not found", req.Method)}
+	}
+}
+
+func (node *Node) blockByTag(tag string) *Block {
+	head := node.blocks[len(node.blocks)-1]
+
+	switch tag {
+	case "latest", "pending":
+		return head
+	case "safe":
+		return node.tagged(node.safe)
+	case "finalized":
+		return node.tagged(node.finalized)
+	case "earliest":
+		return node.blocks[0]
+	}
+
+	number, err := strconv.ParseInt(strings.TrimPrefix(tag, "0x"), 16, 64)
+
+	if err != nil || number > head.Number || number < 0 {
+		return nil
+	}
+
+	return node.blocks[number]
+}
+
+func (node *Node) tagged(number int64) *Block {
+	if number < 0 || number >= int64(len(node.blocks)) {
+		return node.blocks[len(node.blocks)-1]
+	}
+
+	return node.blocks[number]
+}
+
+func (node *Node) logs(from string, to string, addresses []string) []*Log {
+	head := node.blocks[len(node.blocks)-1]
+
+	start := int64(0)
+	end := head.Number
+
+	if block := node.blockByTag(from); block != nil {
+		start = block.Number
+	}
+
+	if block := node.blockByTag(to); block != nil {
+		end = block.Number
+	}
+
+	filter := make(map[string]bool)
+
+	for _, address := range addresses {
+		filter[strings.ToLower(address)] = true
+	}
+
+	logs := make([]*Log, 0)
+
+	for _, block := range node.blocks[start : end+1] {
+		for _, tx := range block.Transactions {
+			receipt, ok := node.receipts[tx.Hash]
+
+			if !ok {
+				continue
+			}
+
+			for _, log := range receipt.Logs {
+				if len(filter) == 0 || filter[strings.ToLower(log.Address)] {
+					logs = append(logs, log)
+				}
+			}
+		}
+	}
+
+	return logs
+}
+
+func (node *Node) encodeBlock(block *Block, full bool) map[string]interface{} {
+	var txs []interface{}
+
+	for _, tx := range block.Transactions {
+		if full {
+			txs = append(txs, tx)
+		} else {
+			txs = append(txs, tx.Hash)
+		}
+	}
+
+	if txs == nil {
+		txs = make([]interface{}, 0)
+	}
+
+	return map[string]interface{}{
+		"number":       hexInt(block.Number),
+		"hash":         block.Hash,
+		"parentHash":   block.ParentHash,
+		"timestamp":    hexInt(block.Timestamp),
+		"transactions": txs,
+	}
+}
+
+func params(req *request, values ...interface{}) *rpcError {
+	for i, value := range values {
+		if i >= len(req.Params) {
+			break
+		}
+
+		if err := json.Unmarshal(req.Params[i], value); err != nil {
+			return &rpcError{Code: -32602, Message: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+func hexInt(n int64) string {
+	return "0x" + strconv.FormatInt(n, 16)
+}
+
+func hash(kind string, n int64, fork int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s-%d-%d", kind, n, fork)))
+	return "0x" + hex.EncodeToString(sum[:])
+}
+
+var txSeq int64
+var txSeqLock sync.Mutex
+
+// Transfer create eth transfer transaction with a unique hash
+func Transfer(from string, to string, value string) *Transaction {
+	txSeqLock.Lock()
+	txSeq++
+	seq := txSeq
+	txSeqLock.Unlock()
+
+	return &Transaction{
+		Hash:     hash("tx", seq, 0),
+		From:     strings.ToLower(from),
+		To:       strings.ToLower(to),
+		Value:    value,
+		Gas:      "0x5208",
+		GasPrice: "0x3b9aca00",
+		Input:    "0x",
+		Nonce:    hexInt(seq),
+	}
+}
+
+// ERC20Transfer create erc20 transfer(to,amount) call transaction, amount is a hex string
+func ERC20Transfer(from string, token string, to string, amount string) *Transaction {
+	tx := Transfer(from, token, "0x0")
+
+	tx.Gas = "0xea60"
+	tx.Input = "0xa9059cbb" + pad(strings.TrimPrefix(strings.ToLower(to), "0x")) + pad(strings.TrimPrefix(amount, "0x"))
+
+	return tx
+}
+
+// encodeString abi encode the string return value
+func encodeString(s string) string {
+	data := hex.EncodeToString([]byte(s))
+
+	if len(data)%64 != 0 {
+		data += strings.Repeat("0", 64-len(data)%64)
+	}
+
+	return "0x" + pad("20") + pad(strconv.FormatInt(int64(len(s)), 16)) + data
+}
+
+func pad(hex string) string {
+	return strings.Repeat("0", 64-len(hex)) + hex
+}
@@ -0,0 +1,97 @@
+package sensorstest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	config "github.com/dynamicgo/go-config"
+	sensors "github.com/laplacenetwork/eth-sensors"
+)
+
+// Notification the recorded notification
+type Notification struct {
+	Key   string // watcher key
+	Order sensors.Order
+}
+
+// Notifier the recording notifier
+type Notifier struct {
+	sync.Mutex
+	notifications []*Notification
+	err           error
+	signal        chan struct{}
+}
+
+// NewNotifier create recording notifier
+func NewNotifier() *Notifier {
+	return &Notifier{
+		signal: make(chan struct{}, 1),
+	}
+}
+
+// Creator get the notifier factory which always return this notifier
+func (notifier *Notifier) Creator() sensors.NotifierF {
+	return func(config config.Config) (sensors.Notifier, error) {
+		return notifier, nil
+	}
+}
+
+// Fail let the notifier return err, nil to recover
+func (notifier *Notifier) Fail(err error) {
+	notifier.Lock()
+	defer notifier.Unlock()
+
+	notifier.err = err
+}
+
+// Notify implement sensors.Notifier
+func (notifier *Notifier) Notify(receiver *sensors.Watcher, order *sensors.Order) error {
+	notifier.Lock()
+	defer notifier.Unlock()
+
+	if notifier.err != nil {
+		return notifier.err
+	}
+
+	notifier.notifications = append(notifier.notifications, &Notification{
+		Key:   receiver.Key,
+		Order: *order,
+	})
+
+	select {
+	case notifier.signal <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Notifications get recorded notifications
+func (notifier *Notifier) Notifications() []*Notification {
+	notifier.Lock()
+	defer notifier.Unlock()
+
+	return append([]*Notification(nil), notifier.notifications...)
+}
+
+// Wait wait for watcher receive notification of tx with status
+func (notifier *Notifier) Wait(key string, tx string, status sensors.Status, timeout time.Duration) (*sensors.Order, error) {
+	deadline := time.After(timeout)
+
+	for {
+		for _, notification := range notifier.Notifications() {
+			if notification.Key == key && notification.Order.TX == tx && notification.Order.Status == status {
+				order := notification.Order
+				return &order, nil
+			}
+		}
+
+		select {
+		case <-notifier.signal:
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			return nil, fmt.Errorf("wait watcher %s tx %s status %s timeout", key, tx, status)
+		}
+	}
+}
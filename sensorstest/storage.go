@@ -0,0 +1,238 @@
+package sensorstest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	config "github.com/dynamicgo/go-config"
+	sensors "github.com/laplacenetwork/eth-sensors"
+)
+
+// Storage the in-memory order storage
+type Storage struct {
+	sync.Mutex
+	orders    map[string]*sensors.Order
+	archived  map[string]*sensors.Order          // archived orders indexed by order id
+	histories map[string][]*sensors.OrderHistory // order transitions indexed by order id
+	seq       int64                              // the last order transition id
+	err       error                              // returned by Update if set
+	conflicts int                                // the next updates failed with version conflict
+}
+
+// NewStorage create in-memory order storage
+func NewStorage() *Storage {
+	return &Storage{
+		orders:    make(map[string]*sensors.Order),
+		archived:  make(map[string]*sensors.Order),
+		histories: make(map[string][]*sensors.OrderHistory),
+	}
+}
+
+// Creator get the storage factory which always return this storage
+func (storage *Storage) Creator() sensors.OrderStorageF {
+	return func(config config.Config, clock sensors.Clock) (sensors.OrderStorage, error) {
+		return storage, nil
+	}
+}
+
+// Fail let the order updates return err, nil to recover
+func (storage *Storage) Fail(err error) {
+	storage.Lock()
+	defer storage.Unlock()
+
+	storage.err = err
+}
+
+// Conflict let the next n order updates fail with sensors.ErrVersion, as if another replica saved the orders
+func (storage *Storage) Conflict(n int) {
+	storage.Lock()
+	defer storage.Unlock()
+
+	storage.conflicts = n
+}
+
+// Save implement sensors.OrderStorage
+func (storage *Storage) Save(order *sensors.Order, histories ...*sensors.OrderHistory) error {
+	storage.Lock()
+	defer storage.Unlock()
+
+	for _, saved := range storage.orders {
+		if saved.ChainID == order.ChainID && saved.TX == order.TX {
+			return nil
+		}
+	}
+
+	if order.Version == 0 {
+		order.Version = 1
+	}
+
+	saved := *order
+
+	storage.orders[order.ID] = &saved
+
+	storage.record(histories)
+
+	return nil
+}
+
+// Update implement sensors.OrderStorage
+func (storage *Storage) Update(order *sensors.Order, histories ...*sensors.OrderHistory) error {
+	storage.Lock()
+	defer storage.Unlock()
+
+	if storage.err != nil {
+		return storage.err
+	}
+
+	if storage.conflicts > 0 {
+		storage.conflicts--
+		return sensors.ErrVersion
+	}
+
+	if saved, ok := storage.orders[order.ID]; !ok || saved.Version != order.Version {
+		return sensors.ErrVersion
+	}
+
+	order.Version++
+
+	saved := *order
+
+	storage.orders[order.ID] = &saved
+
+	storage.record(histories)
+
+	return nil
+}
+
+func (storage *Storage) record(histories []*sensors.OrderHistory) {
+	for _, history := range histories {
+		storage.seq++
+
+		saved := *history
+		saved.ID = storage.seq
+
+		storage.histories[history.OrderID] = append(storage.histories[history.OrderID], &saved)
+	}
+}
+
+// History implement sensors.OrderStorage
+func (storage *Storage) History(orderID string) ([]*sensors.OrderHistory, error) {
+	storage.Lock()
+	defer storage.Unlock()
+
+	histories := make([]*sensors.OrderHistory, 0)
+
+	for _, history := range storage.histories[orderID] {
+		copied := *history
+		histories = append(histories, &copied)
+	}
+
+	return histories, nil
+}
+
+// Unconfirmed implement sensors.OrderStorage
+func (storage *Storage) Unconfirmed() ([]*sensors.Order, error) {
+	return storage.filter(func(order *sensors.Order) bool {
+		return order.Unconfirmed()
+	}), nil
+}
+
+// Find implement sensors.OrderStorage
+func (storage *Storage) Find(chainID int64, tx string) (*sensors.Order, error) {
+	match := func(order *sensors.Order) bool {
+		return order.TX == tx && (chainID == 0 || order.ChainID == chainID)
+	}
+
+	orders := append(storage.filter(match), storage.filterArchived(match)...)
+
+	if len(orders) == 0 {
+		return nil, nil
+	}
+
+	return orders[0], nil
+}
+
+// Range implement sensors.OrderStorage
+func (storage *Storage) Range(chainID int64, from int64, to int64) ([]*sensors.Order, error) {
+	match := func(order *sensors.Order) bool {
+		return order.ChainID == chainID && order.CommitBlock >= from && order.CommitBlock <= to
+	}
+
+	orders := append(storage.filter(match), storage.filterArchived(match)...)
+
+	sortOrders(orders)
+
+	return orders, nil
+}
+
+// Archive implement sensors.OrderStorage
+func (storage *Storage) Archive(before time.Time, limit int) (int, error) {
+	orders := storage.filter(func(order *sensors.Order) bool {
+		return !order.Unconfirmed() && order.CreateTime.Before(before)
+	})
+
+	if len(orders) > limit {
+		orders = orders[:limit]
+	}
+
+	storage.Lock()
+	defer storage.Unlock()
+
+	for _, order := range orders {
+		order.Archived = true
+
+		storage.archived[order.ID] = order
+
+		delete(storage.orders, order.ID)
+	}
+
+	return len(orders), nil
+}
+
+// Orders get all saved orders sorted by commit block
+func (storage *Storage) Orders() []*sensors.Order {
+	return storage.filter(func(order *sensors.Order) bool {
+		return true
+	})
+}
+
+func (storage *Storage) filter(f func(order *sensors.Order) bool) []*sensors.Order {
+	storage.Lock()
+	defer storage.Unlock()
+
+	return collect(storage.orders, f)
+}
+
+func (storage *Storage) filterArchived(f func(order *sensors.Order) bool) []*sensors.Order {
+	storage.Lock()
+	defer storage.Unlock()
+
+	return collect(storage.archived, f)
+}
+
+func collect(orders map[string]*sensors.Order, f func(order *sensors.Order) bool) []*sensors.Order {
+	matched := make([]*sensors.Order, 0)
+
+	for _, order := range orders {
+		if f(order) {
+			copied := *order
+			matched = append(matched, &copied)
+		}
+	}
+
+	sortOrders(matched)
+
+	return matched
+}
+
+// sortOrders sort orders by commit block and id
+func sortOrders(orders []*sensors.Order) {
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].CommitBlock == orders[j].CommitBlock {
+			return orders[i].ID < orders[j].ID
+		}
+
+		return orders[i].CommitBlock < orders[j].CommitBlock
+	})
+}
@@ -0,0 +1,135 @@
+package sensorstest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// wsConn serialize writes of one websocket connection
+type wsConn struct {
+	sync.Mutex
+	conn *websocket.Conn
+}
+
+func (conn *wsConn) write(message interface{}) error {
+	conn.Lock()
+	defer conn.Unlock()
+
+	return conn.conn.WriteJSON(message)
+}
+
+type subscription struct {
+	conn *wsConn
+	id   string
+}
+
+func (sub *subscription) send(result interface{}) error {
+	return sub.conn.write(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "eth_subscription",
+		"params": map[string]interface{}{
+			"subscription": sub.id,
+			"result":       result,
+		},
+	})
+}
+
+// WSURL the node json rpc websocket endpoint, only eth_subscribe newHeads is served
+func (node *Node) WSURL() string {
+	return "ws" + strings.TrimPrefix(node.server.URL, "http")
+}
+
+// DropSubscriptions close all websocket connections, blocks mined before the client reconnect are not announced
+func (node *Node) DropSubscriptions() {
+	node.Lock()
+	subs := node.subs
+	node.subs = nil
+	node.Unlock()
+
+	for _, sub := range subs {
+		sub.conn.conn.Close()
+	}
+}
+
+// Subscriptions the count of live newHeads subscriptions
+func (node *Node) Subscriptions() int {
+	node.Lock()
+	defer node.Unlock()
+
+	return len(node.subs)
+}
+
+func (node *Node) serveWS(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgrader.Upgrade(w, r, nil)
+
+	if err != nil {
+		return
+	}
+
+	defer ws.Close()
+
+	conn := &wsConn{conn: ws}
+
+	defer node.unsubscribe(conn)
+
+	for {
+		var req request
+
+		if err := ws.ReadJSON(&req); err != nil {
+			return
+		}
+
+		var kind string
+
+		if req.Method != "eth_subscribe" || len(req.Params) == 0 || json.Unmarshal(req.Params[0], &kind) != nil {
+			conn.write(&response{ID: req.ID, JSONRPC: "2.0", Error: &rpcError{Code: -32601, Message: "method not supported"}})
+			continue
+		}
+
+		node.Lock()
+		node.counts["eth_subscribe"]++
+		id := hexInt(int64(node.counts["eth_subscribe"]))
+
+		sub := &subscription{conn: conn, id: id}
+
+		if kind == "newHeads" {
+			node.subs = append(node.subs, sub)
+		}
+
+		node.Unlock()
+
+		conn.write(&response{ID: req.ID, JSONRPC: "2.0", Result: id})
+	}
+}
+
+func (node *Node) unsubscribe(conn *wsConn) {
+	node.Lock()
+	defer node.Unlock()
+
+	subs := node.subs[:0]
+
+	for _, sub := range node.subs {
+		if sub.conn != conn {
+			subs = append(subs, sub)
+		}
+	}
+
+	node.subs = subs
+}
+
+// announce send the new head to subscribers, must be called with node locked
+func (node *Node) announce(block *Block) {
+	head := node.encodeBlock(block, false)
+
+	for _, sub := range node.subs {
+		go sub.send(head)
+	}
+}
@@ -0,0 +1,181 @@
+package source
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	config "github.com/dynamicgo/go-config"
+	"github.com/dynamicgo/slf4go"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/openzknetwork/ethgo/rpc"
+)
+
+// Errors
+var (
+	ErrFilePath = errors.New("block file path required")
+)
+
+// maximum size of one recorded block line
+const maxBlockLine = 64 * 1024 * 1024
+
+// fileSource replay recorded blocks, the file contains one eth_getBlockByNumber (with full txs) result per line,
+// gzip compressed if the path ends with .gz
+type fileSource struct {
+	slf4go.Logger
+	path   string
+	from   int64
+	to     int64 // -1 means the end of file
+	chain  sensors.SourceChain
+	rewind chan int64
+	closed chan struct{}
+}
+
+// NewFile create block replay source with config "file" section:
+// path: the recorded blocks file
+// from/to: replay blocks in range [from,to], default the whole file
+func NewFile(config config.Config, chain sensors.SourceChain) (sensors.BlockSource, error) {
+	path := config.Get("file", "path").String("")
+
+	if path == "" {
+		return nil, ErrFilePath
+	}
+
+	return &fileSource{
+		Logger: slf4go.Get("file-source-" + strconv.FormatInt(chain.ID(), 10)),
+		path:   path,
+		from:   int64(config.Get("file", "from").Int(0)),
+		to:     int64(config.Get("file", "to").Int(-1)),
+		chain:  chain,
+		rewind: make(chan int64, 1),
+		closed: make(chan struct{}),
+	}, nil
+}
+
+// Run replay the file, then wait for rewinding until closed
+func (source *fileSource) Run() error {
+	from := source.from
+
+	for {
+		rewind, err := source.replay(from)
+
+		if err != nil {
+			source.ErrorF("replay %s err: %s", source.path, err)
+			return err
+		}
+
+		if rewind < 0 {
+			source.InfoF("replay %s from block %d -- completed", source.path, from)
+
+			select {
+			case rewind = <-source.rewind:
+			case <-source.closed:
+				return nil
+			}
+		}
+
+		from = rewind
+	}
+}
+
+func (source *fileSource) Rewind(block int64) error {
+	// keep the latest rewind target only
+	select {
+	case <-source.rewind:
+	default:
+	}
+
+	source.rewind <- block
+
+	return nil
+}
+
+func (source *fileSource) Close() error {
+	select {
+	case <-source.closed:
+	default:
+		close(source.closed)
+	}
+
+	return nil
+}
+
+// replay handle blocks from block, return the rewind target if rewinding while replaying otherwise -1
+func (source *fileSource) replay(from int64) (int64, error) {
+	file, err := os.Open(source.path)
+
+	if err != nil {
+		return -1, err
+	}
+
+	defer file.Close()
+
+	var reader io.Reader = file
+
+	if strings.HasSuffix(source.path, ".gz") {
+		gz, err := gzip.NewReader(file)
+
+		if err != nil {
+			return -1, err
+		}
+
+		defer gz.Close()
+
+		reader = gz
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxBlockLine)
+
+	for line := 1; scanner.Scan(); line++ {
+		select {
+		case rewind := <-source.rewind:
+			return rewind, nil
+		case <-source.closed:
+			return -1, nil
+		default:
+		}
+
+		data := scanner.Bytes()
+
+		if len(strings.TrimSpace(string(data))) == 0 {
+			continue
+		}
+
+		block := new(rpc.Block)
+
+		if err := json.Unmarshal(data, block); err != nil {
+			return -1, fmt.Errorf("decode line %d err: %s", line, err)
+		}
+
+		number, err := strconv.ParseInt(strings.TrimPrefix(block.Number, "0x"), 16, 64)
+
+		if err != nil {
+			return -1, fmt.Errorf("decode line %d block number %s err: %s", line, block.Number, err)
+		}
+
+		if number < from {
+			continue
+		}
+
+		if source.to >= 0 && number > source.to {
+			break
+		}
+
+		if err := source.chain.Handle(block); err != nil {
+			return -1, fmt.Errorf("handle block %d err: %s", number, err)
+		}
+	}
+
+	return -1, scanner.Err()
+}
+
+func init() {
+	sensors.RegisterBlockSource("file", NewFile)
+}
@@ -0,0 +1,180 @@
+package source
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/dynamicgo/go-config"
+	"github.com/dynamicgo/slf4go"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/laplacenetwork/eth-sensors/prefetch"
+	"github.com/openzknetwork/ethgo/rpc"
+	"github.com/openzknetwork/indexer"
+	ethfetcher "github.com/openzknetwork/indexer/eth"
+)
+
+type rewinder interface {
+	Rewind(block int64) error
+}
+
+type closer interface {
+	Close() error
+}
+
+// indexerSource feed the blocks polled by the indexer, the blocks skipped by the indexer since the chain cursor
+// (e.g. mined while the sensor stopped) are fetched with prefetching before the indexed block
+type indexerSource struct {
+	sync.Mutex
+	slf4go.Logger
+	indexer    indexer.Indexer
+	chain      sensors.SourceChain
+	prefetcher *prefetch.Prefetcher
+	next       int64 // the next block to handle, -1 means load the chain cursor
+	resuming   bool  // the indexer not reach the chain cursor yet, the handled blocks are skipped
+}
+
+// NewIndexer create block source polling the chain json rpc with openzknetwork indexer,
+// the gap since the chain cursor is prefetched with config "prefetch" section workers/window/memory/timeout
+func NewIndexer(config config.Config, chain sensors.SourceChain) (sensors.BlockSource, error) {
+	label := strconv.FormatInt(chain.ID(), 10)
+
+	source := &indexerSource{
+		Logger: slf4go.Get("indexer-source-" + label),
+		chain:  chain,
+		next:   -1,
+		prefetcher: prefetch.NewPrefetcher(
+			prefetch.RPC("indexer", label, chain.Ethnode(), config.Get("prefetch", "timeout").Duration(10*time.Second)),
+			config.Get("prefetch", "workers").Int(8),
+			int64(config.Get("prefetch", "window").Int(64)),
+			int64(config.Get("prefetch", "memory").Int(64*1024*1024)),
+		),
+	}
+
+	fetcher := ethfetcher.New(chain.Ethnode(), ethfetcher.HandleFunc(source.handle))
+
+	idx, err := indexer.New(config, fetcher)
+
+	if err != nil {
+		return nil, err
+	}
+
+	source.indexer = idx
+
+	return source, nil
+}
+
+func (source *indexerSource) Run() error {
+	source.indexer.Run()
+
+	return nil
+}
+
+func (source *indexerSource) Rewind(block int64) error {
+	idx, ok := source.indexer.(rewinder)
+
+	if !ok {
+		return sensors.ErrNotSupport
+	}
+
+	if err := idx.Rewind(block); err != nil {
+		return err
+	}
+
+	source.Lock()
+	source.next = block
+	source.resuming = false
+	source.Unlock()
+
+	return nil
+}
+
+func (source *indexerSource) Close() error {
+	idx, ok := source.indexer.(closer)
+
+	if !ok {
+		return sensors.ErrNotSupport
+	}
+
+	return idx.Close()
+}
+
+// handle the indexed block after the blocks skipped since the last handled one
+func (source *indexerSource) handle(block *rpc.Block) error {
+	number, err := strconv.ParseInt(strings.TrimPrefix(block.Number, "0x"), 16, 64)
+
+	if err != nil {
+		return err
+	}
+
+	next, resuming, err := source.expected(number)
+
+	if err != nil {
+		return err
+	}
+
+	if number < next {
+		if resuming {
+			source.DebugF("skip block %d handled before the chain cursor %d", number, next-1)
+			return nil
+		}
+	} else if number > next {
+		source.InfoF("catch up blocks [%d,%d] with prefetching", next, number-1)
+
+		stopped, err := source.prefetcher.Run(next, number-1, source.chain.Handle)
+
+		if err != nil {
+			return err
+		}
+
+		if stopped < number {
+			return fmt.Errorf("catch up block %d not found", stopped)
+		}
+	}
+
+	if err := source.chain.Handle(block); err != nil {
+		return err
+	}
+
+	source.Lock()
+	source.next = number + 1
+	source.resuming = false
+	source.Unlock()
+
+	return nil
+}
+
+// expected get the next block to handle, resume from the block after the chain cursor at first,
+// or from the indexed block if no block processed
+func (source *indexerSource) expected(number int64) (int64, bool, error) {
+	source.Lock()
+	next, resuming := source.next, source.resuming
+	source.Unlock()
+
+	if next >= 0 {
+		return next, resuming, nil
+	}
+
+	cursor, _, err := source.chain.Cursor()
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	if cursor < 0 {
+		return number, false, nil
+	}
+
+	source.Lock()
+	source.next = cursor + 1
+	source.resuming = true
+	source.Unlock()
+
+	return cursor + 1, true, nil
+}
+
+func init() {
+	sensors.RegisterBlockSource("indexer", NewIndexer)
+}
@@ -0,0 +1,87 @@
+package source
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dynamicgo/slf4go"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/laplacenetwork/eth-sensors/prefetch"
+	"github.com/openzknetwork/ethgo/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+// cursorChain the fake chain recording the handled blocks and persisting the last one as cursor
+type cursorChain struct {
+	sensors.SourceChain
+	handled []int64
+	cursor  int64
+}
+
+func (chain *cursorChain) Handle(block *rpc.Block) error {
+	number, _ := strconv.ParseInt(strings.TrimPrefix(block.Number, "0x"), 16, 64)
+
+	chain.handled = append(chain.handled, number)
+	chain.cursor = number
+
+	return nil
+}
+
+func (chain *cursorChain) Cursor() (int64, string, error) {
+	if chain.cursor < 0 {
+		return -1, "", nil
+	}
+
+	return chain.cursor, "0x" + strconv.FormatInt(chain.cursor, 16), nil
+}
+
+func newBlock(number int64) *rpc.Block {
+	return &rpc.Block{Number: "0x" + strconv.FormatInt(number, 16)}
+}
+
+func newTestIndexer(chain sensors.SourceChain, fetched *[]int64) *indexerSource {
+	return &indexerSource{
+		Logger: slf4go.Get("indexer-source-test"),
+		chain:  chain,
+		next:   -1,
+		prefetcher: prefetch.NewPrefetcher(func(number int64) (*rpc.Block, int64, error) {
+			*fetched = append(*fetched, number)
+			return newBlock(number), 1, nil
+		}, 1, 4, 1024),
+	}
+}
+
+func TestIndexerRestartGap(t *testing.T) {
+	chain := &cursorChain{cursor: -1}
+
+	var fetched []int64
+
+	// the first run start from the indexed block
+	source := newTestIndexer(chain, &fetched)
+
+	for number := int64(10); number <= 12; number++ {
+		require.NoError(t, source.handle(newBlock(number)))
+	}
+
+	require.Equal(t, []int64{10, 11, 12}, chain.handled)
+	require.Empty(t, fetched)
+
+	// restarted after blocks 13~15 mined, the indexer resume behind the cursor and then skip the gap
+	chain.handled = nil
+
+	source = newTestIndexer(chain, &fetched)
+
+	require.NoError(t, source.handle(newBlock(11)))
+	require.NoError(t, source.handle(newBlock(12)))
+	require.NoError(t, source.handle(newBlock(16)))
+	require.NoError(t, source.handle(newBlock(17)))
+
+	require.Equal(t, []int64{13, 14, 15, 16, 17}, chain.handled)
+	require.Equal(t, []int64{13, 14, 15}, fetched)
+
+	// the blocks before the cursor are handled again after resumed
+	require.NoError(t, source.handle(newBlock(17)))
+
+	require.Equal(t, int64(17), chain.handled[len(chain.handled)-1])
+}
@@ -0,0 +1,53 @@
+package source
+
+import (
+	"strconv"
+
+	config "github.com/dynamicgo/go-config"
+	extend "github.com/dynamicgo/go-config-extend"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/laplacenetwork/eth-sensors/subscriber"
+)
+
+type websocketSource struct {
+	subscriber *subscriber.Subscriber
+}
+
+// NewWebSocket create block source triggered by websocket newHeads subscription, config "websocket" section
+func NewWebSocket(config config.Config, chain sensors.SourceChain) (sensors.BlockSource, error) {
+	wsConfig, err := extend.SubConfig(config, "websocket")
+
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := subscriber.New(strconv.FormatInt(chain.ID(), 10), chain.Ethnode(), wsConfig, chain.Handle, chain.Contracts, chain.Cursor)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &websocketSource{
+		subscriber: sub,
+	}, nil
+}
+
+func (source *websocketSource) Run() error {
+	source.subscriber.Run()
+
+	return nil
+}
+
+func (source *websocketSource) Rewind(block int64) error {
+	return source.subscriber.Rewind(block)
+}
+
+func (source *websocketSource) Close() error {
+	source.subscriber.Close()
+
+	return nil
+}
+
+func init() {
+	sensors.RegisterBlockSource("websocket", NewWebSocket)
+}
@@ -0,0 +1,87 @@
+package sensors
+
+import (
+	"fmt"
+	"time"
+)
+
+// transitions the allowed order status transitions, the terminal statuses only change by recheck
+var transitions = map[Status][]Status{
+	StatusCreated:  {StatusPending, StatusRunning, StatusIncluded, StatusCanceled},
+	StatusPending:  {StatusRunning, StatusIncluded, StatusFailed, StatusCanceled},
+	StatusRunning:  {StatusSucceed, StatusFailed},
+	StatusIncluded: {StatusSafe, StatusFinalized, StatusFailed},
+	// safe orders wait for finalized, or confirmed with the safe tag
+	StatusSafe:      {StatusSafe, StatusFinalized, StatusFailed},
+	StatusSucceed:   {StatusFailed},
+	StatusFinalized: {StatusFailed},
+	StatusFailed:    {StatusSucceed, StatusSafe, StatusFinalized},
+}
+
+// TransitionError the illegal order status transition
+type TransitionError struct {
+	Order string // order id
+	From  Status
+	To    Status
+}
+
+func (err *TransitionError) Error() string {
+	return fmt.Sprintf("order %s illegal status transition %s -> %s", err.Order, err.From, err.To)
+}
+
+// OrderHistory the order status transition record
+type OrderHistory struct {
+	ID      int64     `xorm:"pk autoincr"`
+	OrderID string    `xorm:"index"`
+	ChainID int64     `xorm:""`
+	TX      string    `xorm:"index"`
+	From    Status    `xorm:""`
+	To      Status    `xorm:""`
+	Block   int64     `xorm:""` // the block triggered the transition
+	Time    time.Time `xorm:""` // the block time or the operation time
+	Reason  string    `xorm:""` // the failure reason, or the operation name like recheck
+}
+
+// TableName .
+func (table *OrderHistory) TableName() string {
+	return "eth_sensors_order_history"
+}
+
+// CanTransit check if the order status transition is allowed
+func CanTransit(from Status, to Status) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Transit move the order to status, return the transition record to save with the order,
+// return *TransitionError and keep the order unchanged if the transition is illegal
+func (table *Order) Transit(to Status, block int64, time time.Time, reason string) (*OrderHistory, error) {
+	if !CanTransit(table.Status, to) {
+		return nil, &TransitionError{Order: table.ID, From: table.Status, To: to}
+	}
+
+	history := &OrderHistory{
+		OrderID: table.ID,
+		ChainID: table.ChainID,
+		TX:      table.TX,
+		From:    table.Status,
+		To:      to,
+		Block:   block,
+		Time:    time,
+		Reason:  reason,
+	}
+
+	table.Status = to
+
+	return history, nil
+}
+
+// Rollback restore the order status of the unsaved transition
+func (table *Order) Rollback(history *OrderHistory) {
+	table.Status = history.From
+}
@@ -1,28 +1,39 @@
 package storage
 
 import (
+	"sort"
+	"time"
+
 	config "github.com/dynamicgo/go-config"
 	"github.com/dynamicgo/slf4go"
 	"github.com/dynamicgo/xorm-decorator"
 	"github.com/go-xorm/xorm"
 	sensors "github.com/laplacenetwork/eth-sensors"
+	"github.com/laplacenetwork/eth-sensors/metrics"
 )
 
 type storageImpl struct {
 	slf4go.Logger
 	engine *xorm.Engine
+	clock  sensors.Clock
 }
 
 // New .
-func New(config config.Config) (sensors.OrderStorage, error) {
-	return NewDBStorage(
+func New(config config.Config, clock sensors.Clock) (sensors.OrderStorage, error) {
+	return NewDBStorageWithClock(
 		config.Get("database", "driver").String("sqlite3"),
 		config.Get("database", "source").String("../../.build/sensor.db"),
+		clock,
 	)
 }
 
 // NewDBStorage create new database order storage
 func NewDBStorage(driver, source string) (sensors.OrderStorage, error) {
+	return NewDBStorageWithClock(driver, source, sensors.SystemClock)
+}
+
+// NewDBStorageWithClock create new database order storage, the clock is used to stamp orders without create time
+func NewDBStorageWithClock(driver, source string, clock sensors.Clock) (sensors.OrderStorage, error) {
 
 	engine, err := xorm.NewEngine(driver, source)
 
@@ -33,6 +44,7 @@ func NewDBStorage(driver, source string) (sensors.OrderStorage, error) {
 	storage := &storageImpl{
 		Logger: slf4go.Get("storage"),
 		engine: engine,
+		clock:  clock,
 	}
 
 	return storage, nil
@@ -42,39 +54,122 @@ func (storage *storageImpl) Unconfirmed() ([]*sensors.Order, error) {
 
 	orders := make([]*sensors.Order, 0)
 
+	start := time.Now()
+
+	// safe orders still wait for the finalized block if not confirmed
 	err := storage.engine.Where(
-		`"status" = ? or "status" = ?`,
+		`"status" in (?, ?, ?) or ("status" = ? and "confirm_block" < 0)`,
 		sensors.StatusPending,
-		sensors.StatusRunning).Find(&orders)
+		sensors.StatusRunning,
+		sensors.StatusIncluded,
+		sensors.StatusSafe).Find(&orders)
+
+	metrics.ObserveDB("storage", "unconfirmed", start, err)
 
 	return orders, err
 }
 
-func (storage *storageImpl) Save(order *sensors.Order) error {
-	_, err := storage.engine.InsertOne(order)
+func (storage *storageImpl) Save(order *sensors.Order, histories ...*sensors.OrderHistory) error {
+	if order.CreateTime.IsZero() {
+		order.CreateTime = storage.clock.Now()
+	}
+
+	if order.Version == 0 {
+		order.Version = 1
+	}
+
+	start := time.Now()
+
+	err := storage.transaction(func(session *xorm.Session) error {
+		if _, err := session.InsertOne(order); err != nil {
+			return err
+		}
+
+		return storage.record(session, histories)
+	})
+
+	metrics.ObserveDB("storage", "save", start, err)
 
 	if decorator.DuplicateKey(storage.engine, err) {
-		storage.WarnF("save order %s err for duplicate tx %s", order.TX)
+		storage.WarnF("save order %s err for duplicate tx %s", order.ID, order.TX)
 		return nil
 	}
 
 	return err
 }
 
-func (storage *storageImpl) Update(order *sensors.Order) error {
-	_, err := storage.engine.Where(`"i_d" = ?`, order.ID).Update(order)
+func (storage *storageImpl) Update(order *sensors.Order, histories ...*sensors.OrderHistory) error {
+	start := time.Now()
+
+	version := order.Version
+
+	order.Version++
+
+	err := storage.transaction(func(session *xorm.Session) error {
+		// update all columns, the cleared reasons and zero blocks are written too
+		affected, err := session.Where(`"i_d" = ? and "version" = ?`, order.ID, version).AllCols().Update(order)
+
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return sensors.ErrVersion
+		}
+
+		return storage.record(session, histories)
+	})
+
+	metrics.ObserveDB("storage", "update", start, err)
 
 	if err != nil {
+		order.Version = version
+		return err
+	}
+
+	return nil
+}
+
+// transaction run f in database transaction
+func (storage *storageImpl) transaction(f func(session *xorm.Session) error) error {
+	session := storage.engine.NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return err
+	}
+
+	if err := f(session); err != nil {
+		session.Rollback()
 		return err
 	}
 
-	// if affected == 0 {
-	// 	return sensors.ErrVersion
-	// }
+	return session.Commit()
+}
+
+// record insert the order transitions
+func (storage *storageImpl) record(session *xorm.Session, histories []*sensors.OrderHistory) error {
+	for _, history := range histories {
+		if _, err := session.InsertOne(history); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+func (storage *storageImpl) History(orderID string) ([]*sensors.OrderHistory, error) {
+	histories := make([]*sensors.OrderHistory, 0)
+
+	start := time.Now()
+
+	err := storage.engine.Where(`"order_i_d" = ?`, orderID).Asc("i_d").Find(&histories)
+
+	metrics.ObserveDB("storage", "history", start, err)
+
+	return histories, err
+}
+
 func (storage *storageImpl) Get(id string) (*sensors.Order, error) {
 	var order sensors.Order
 
@@ -91,6 +186,139 @@ func (storage *storageImpl) Get(id string) (*sensors.Order, error) {
 	return &order, nil
 }
 
+func (storage *storageImpl) Find(chainID int64, tx string) (*sensors.Order, error) {
+	var order sensors.Order
+
+	ok, err := storage.byTX(chainID, tx).Get(&order)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return storage.findArchived(chainID, tx)
+	}
+
+	return &order, nil
+}
+
+// byTX the query of the chain order by tx, chain 0 matches any chain
+func (storage *storageImpl) byTX(chainID int64, tx string) *xorm.Session {
+	session := storage.engine.Where(`"t_x" = ?`, tx)
+
+	if chainID != 0 {
+		session = session.And(`"chain_i_d" = ?`, chainID)
+	}
+
+	return session
+}
+
+func (storage *storageImpl) findArchived(chainID int64, tx string) (*sensors.Order, error) {
+	var archived sensors.ArchivedOrder
+
+	start := time.Now()
+
+	ok, err := storage.byTX(chainID, tx).Get(&archived)
+
+	metrics.ObserveDB("storage", "find_archived", start, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, nil
+	}
+
+	archived.Order.Archived = true
+
+	return &archived.Order, nil
+}
+
+func (storage *storageImpl) Range(chainID int64, from int64, to int64) ([]*sensors.Order, error) {
+
+	orders := make([]*sensors.Order, 0)
+
+	err := storage.engine.
+		Where(`"chain_i_d" = ? and "commit_block" >= ? and "commit_block" <= ?`, chainID, from, to).
+		Asc("commit_block").
+		Find(&orders)
+
+	if err != nil {
+		return nil, err
+	}
+
+	archived := make([]*sensors.ArchivedOrder, 0)
+
+	err = storage.engine.
+		Where(`"chain_i_d" = ? and "commit_block" >= ? and "commit_block" <= ?`, chainID, from, to).
+		Find(&archived)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, order := range archived {
+		order.Order.Archived = true
+		orders = append(orders, &order.Order)
+	}
+
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].CommitBlock < orders[j].CommitBlock
+	})
+
+	return orders, nil
+}
+
+// Archive move the terminal orders with their archive time in one transaction, the order transitions are kept
+func (storage *storageImpl) Archive(before time.Time, limit int) (int, error) {
+	orders := make([]*sensors.Order, 0)
+
+	start := time.Now()
+
+	err := storage.transaction(func(session *xorm.Session) error {
+		err := session.Where(
+			`("status" in (?, ?, ?, ?) or ("status" = ? and "confirm_block" >= 0)) and "create_time" < ?`,
+			sensors.StatusSucceed,
+			sensors.StatusFailed,
+			sensors.StatusCanceled,
+			sensors.StatusFinalized,
+			sensors.StatusSafe,
+			before.In(storage.engine.TZLocation)).
+			Asc("create_time").
+			Limit(limit).
+			Find(&orders)
+
+		if err != nil || len(orders) == 0 {
+			return err
+		}
+
+		archiveTime := storage.clock.Now()
+
+		ids := make([]interface{}, 0, len(orders))
+
+		for _, order := range orders {
+			if _, err := session.InsertOne(&sensors.ArchivedOrder{Order: *order, ArchiveTime: archiveTime}); err != nil {
+				return err
+			}
+
+			ids = append(ids, order.ID)
+		}
+
+		_, err = session.In("i_d", ids...).Delete(new(sensors.Order))
+
+		return err
+	})
+
+	metrics.ObserveDB("storage", "archive", start, err)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(orders), nil
+}
+
 func init() {
 	sensors.RegisterStorage("db-storage", New)
 }
@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-xorm/xorm"
+	sensors "github.com/laplacenetwork/eth-sensors"
+	schema "github.com/laplacenetwork/eth-sensors/db"
+	"github.com/laplacenetwork/eth-sensors/sensorstest"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type testStorage struct {
+	*storageImpl
+	clock *sensorstest.Clock
+	dir   string
+}
+
+// newTestStorage create storage on the migrated sqlite database
+func newTestStorage(t *testing.T) *testStorage {
+	dir, err := ioutil.TempDir("", "eth-sensors-storage")
+
+	require.NoError(t, err)
+
+	source := filepath.Join(dir, "sensors.db")
+
+	engine, err := xorm.NewEngine("sqlite3", source)
+
+	require.NoError(t, err)
+	require.NoError(t, schema.Migrate(engine, schema.Latest()))
+
+	engine.Close()
+
+	clock := sensorstest.NewClock(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	storage, err := NewDBStorageWithClock("sqlite3", source, clock)
+
+	require.NoError(t, err)
+
+	return &testStorage{
+		storageImpl: storage.(*storageImpl),
+		clock:       clock,
+		dir:         dir,
+	}
+}
+
+func (storage *testStorage) close() {
+	storage.engine.Close()
+	os.RemoveAll(storage.dir)
+}
+
+func newOrder(id string, chainID int64, tx string, block int64) *sensors.Order {
+	return &sensors.Order{
+		ID:           id,
+		ChainID:      chainID,
+		TX:           tx,
+		PendingBlock: block,
+		CommitBlock:  block,
+		ConfirmBlock: -1,
+		Status:       sensors.StatusRunning,
+	}
+}
+
+func TestFindByChain(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.close()
+
+	require.NoError(t, storage.Save(newOrder("O_1", 1, "0x1", 10)))
+	require.NoError(t, storage.Save(newOrder("O_2", 137, "0x1", 20)))
+
+	order, err := storage.Find(137, "0x1")
+
+	require.NoError(t, err)
+	require.Equal(t, "O_2", order.ID)
+
+	order, err = storage.Find(1, "0x1")
+
+	require.NoError(t, err)
+	require.Equal(t, "O_1", order.ID)
+
+	order, err = storage.Find(56, "0x1")
+
+	require.NoError(t, err)
+	require.Nil(t, order)
+
+	order, err = storage.Find(0, "0x1")
+
+	require.NoError(t, err)
+	require.NotNil(t, order)
+}
+
+func TestUpdateConflict(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.close()
+
+	blockTime := storage.clock.Now()
+
+	order := newOrder("O_1", 1, "0x1", 10)
+	order.FailureReason = sensors.FailureTimeout
+	order.TimeoutReason = sensors.TimeoutBlock
+
+	require.NoError(t, storage.Save(order))
+
+	first, err := storage.Find(1, "0x1")
+
+	require.NoError(t, err)
+
+	second, err := storage.Find(1, "0x1")
+
+	require.NoError(t, err)
+
+	// the cleared reasons are written
+	history, err := first.Transit(sensors.StatusSucceed, 12, blockTime, "")
+
+	require.NoError(t, err)
+
+	first.FailureReason = ""
+	first.TimeoutReason = ""
+	first.ConfirmBlock = 12
+
+	require.NoError(t, storage.Update(first, history))
+	require.Equal(t, int64(2), first.Version)
+
+	saved, err := storage.Find(1, "0x1")
+
+	require.NoError(t, err)
+	require.Equal(t, sensors.StatusSucceed, saved.Status)
+	require.Equal(t, sensors.FailureReason(""), saved.FailureReason)
+	require.Equal(t, sensors.TimeoutReason(""), saved.TimeoutReason)
+	require.Equal(t, int64(2), saved.Version)
+
+	// the stale order is rejected with its version and transition rolled back
+	stale, err := second.Transit(sensors.StatusFailed, 12, blockTime, "stale")
+
+	require.NoError(t, err)
+	require.Equal(t, sensors.ErrVersion, storage.Update(second, stale))
+	require.Equal(t, int64(1), second.Version)
+
+	saved, err = storage.Find(1, "0x1")
+
+	require.NoError(t, err)
+	require.Equal(t, sensors.StatusSucceed, saved.Status)
+
+	histories, err := storage.History("O_1")
+
+	require.NoError(t, err)
+	require.Len(t, histories, 1)
+	require.Equal(t, sensors.StatusRunning, histories[0].From)
+	require.Equal(t, sensors.StatusSucceed, histories[0].To)
+}
+
+func TestHistory(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.close()
+
+	blockTime := storage.clock.Now()
+
+	order := newOrder("O_1", 1, "0x1", 10)
+	order.Status = sensors.StatusCreated
+
+	running, err := order.Transit(sensors.StatusRunning, 10, blockTime, "")
+
+	require.NoError(t, err)
+	require.NoError(t, storage.Save(order, running))
+
+	failed, err := order.Transit(sensors.StatusFailed, 12, blockTime, string(sensors.FailureReverted))
+
+	require.NoError(t, err)
+	require.NoError(t, storage.Update(order, failed))
+
+	succeed, err := order.Transit(sensors.StatusSucceed, 13, blockTime, "recheck")
+
+	require.NoError(t, err)
+	require.NoError(t, storage.Update(order, succeed))
+
+	histories, err := storage.History("O_1")
+
+	require.NoError(t, err)
+	require.Len(t, histories, 3)
+
+	for i, status := range []sensors.Status{sensors.StatusRunning, sensors.StatusFailed, sensors.StatusSucceed} {
+		require.Equal(t, status, histories[i].To)
+		require.Equal(t, "0x1", histories[i].TX)
+	}
+
+	require.Equal(t, sensors.StatusCreated, histories[0].From)
+	require.Equal(t, "recheck", histories[2].Reason)
+
+	histories, err = storage.History("O_2")
+
+	require.NoError(t, err)
+	require.Empty(t, histories)
+}
+
+func TestArchive(t *testing.T) {
+	storage := newTestStorage(t)
+	defer storage.close()
+
+	now := storage.clock.Now()
+
+	orders := []*sensors.Order{
+		newOrder("O_1", 1, "0x1", 10),
+		newOrder("O_2", 1, "0x2", 11),
+		newOrder("O_3", 1, "0x3", 12),
+		newOrder("O_4", 1, "0x4", 13),
+		newOrder("O_5", 1, "0x5", 14),
+	}
+
+	orders[0].Status = sensors.StatusSucceed
+	orders[0].CreateTime = now.Add(-2 * time.Hour)
+	orders[1].Status = sensors.StatusFailed
+	orders[1].CreateTime = now.Add(-time.Hour)
+	orders[2].Status = sensors.StatusSafe
+	orders[2].ConfirmBlock = 12
+	orders[2].CreateTime = now
+	// running and created after the archive time
+	orders[3].CreateTime = now.Add(-time.Hour)
+	orders[4].Status = sensors.StatusCanceled
+	orders[4].CreateTime = now.Add(2 * time.Hour)
+
+	for _, order := range orders {
+		require.NoError(t, storage.Save(order))
+	}
+
+	before := now.Add(time.Hour)
+
+	// the oldest terminal orders first
+	archived, err := storage.Archive(before, 2)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, archived)
+
+	order, err := storage.Get("O_1")
+
+	require.NoError(t, err)
+	require.Nil(t, order)
+
+	archived, err = storage.Archive(before, 2)
+
+	require.NoError(t, err)
+	require.Equal(t, 1, archived)
+
+	archived, err = storage.Archive(before, 2)
+
+	require.NoError(t, err)
+	require.Equal(t, 0, archived)
+
+	// the archived orders are found read only
+	order, err = storage.Find(1, "0x3")
+
+	require.NoError(t, err)
+	require.True(t, order.Archived)
+	require.Equal(t, "O_3", order.ID)
+	require.Equal(t, sensors.StatusSafe, order.Status)
+
+	order, err = storage.Find(0, "0x4")
+
+	require.NoError(t, err)
+	require.False(t, order.Archived)
+
+	order, err = storage.Find(137, "0x1")
+
+	require.NoError(t, err)
+	require.Nil(t, order)
+
+	ranged, err := storage.Range(1, 11, 14)
+
+	require.NoError(t, err)
+	require.Len(t, ranged, 4)
+
+	for i, id := range []string{"O_2", "O_3", "O_4", "O_5"} {
+		require.Equal(t, id, ranged[i].ID)
+		require.Equal(t, i < 2, ranged[i].Archived)
+	}
+}
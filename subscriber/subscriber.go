@@ -0,0 +1,504 @@
+package subscriber
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/dynamicgo/go-config"
+	"github.com/dynamicgo/slf4go"
+	"github.com/gorilla/websocket"
+	"github.com/laplacenetwork/eth-sensors/metrics"
+	"github.com/laplacenetwork/eth-sensors/prefetch"
+	"github.com/laplacenetwork/eth-sensors/rpcpool"
+	"github.com/openzknetwork/ethgo/rpc"
+)
+
+// Errors
+var (
+	ErrURL     = errors.New("websocket url required")
+	errRewound = errors.New("rewound while prefetching")
+)
+
+// Handler handle one fetched block
+type Handler func(block *rpc.Block) error
+
+// Addresses get the contract addresses to subscribe logs for
+type Addresses func() ([]string, error)
+
+// Cursor get the last processed block to resume from, block is -1 if no block processed
+type Cursor func() (block int64, hash string, err error)
+
+// Subscriber subscribe newHeads (and logs of watched contracts) over websocket to trigger block processing,
+// blocks are always fetched over http by number, so the gap while the subscription dropped is filled by the next fetch,
+// the parent hash of every block is checked against the handled block to step back over the reorged blocks
+type Subscriber struct {
+	sync.Mutex
+	slf4go.Logger
+	chain      string
+	url        string // websocket url
+	ethnode    string // http json rpc url
+	client     *http.Client
+	handler    Handler
+	addresses  Addresses
+	cursor     Cursor
+	next       int64             // next block to fetch, -1 means resume from the cursor
+	hashes     map[int64]string  // the recent handled block hashes indexed by number
+	parents    map[string]string // the parent hashes of the fetched blocks not handled yet
+	depth      int64             // the handled block hashes kept to detect reorg
+	reconnect  time.Duration
+	poll       time.Duration
+	wakeup     chan struct{}
+	closed     chan struct{}
+	conn       *websocket.Conn
+	prefetcher *prefetch.Prefetcher
+	threshold  int64 // prefetch blocks in parallel if lag behind head more than threshold blocks
+}
+
+// New create subscriber with config:
+// url: the websocket endpoint url
+// start: the first block to fetch, default resume from the block after the cursor, or the chain head if no block processed
+// depth: the recent handled block hashes kept to detect reorg, default 128
+// reconnect: delay between reconnecting
+// poll: fallback http polling interval, keep the blocks flowing while the subscription is down
+// logs: subscribe logs of the erc20 contracts, default true
+// prefetch: catch-up prefetching, threshold(lag blocks to start prefetching, zero disable)/workers/window/memory
+func New(chain string, ethnode string, config config.Config, handler Handler, addresses Addresses, cursor Cursor) (*Subscriber, error) {
+	url := config.Get("url").String("")
+
+	if url == "" {
+		return nil, ErrURL
+	}
+
+	if !config.Get("logs").Bool(true) {
+		addresses = nil
+	}
+
+	subscriber := &Subscriber{
+		Logger:    slf4go.Get("subscriber-" + chain),
+		chain:     chain,
+		url:       url,
+		ethnode:   ethnode,
+		client:    &http.Client{Timeout: config.Get("timeout").Duration(10 * time.Second)},
+		handler:   handler,
+		addresses: addresses,
+		cursor:    cursor,
+		next:      int64(config.Get("start").Int(-1)),
+		hashes:    make(map[int64]string),
+		parents:   make(map[string]string),
+		depth:     int64(config.Get("depth").Int(128)),
+		reconnect: config.Get("reconnect").Duration(3 * time.Second),
+		poll:      config.Get("poll").Duration(30 * time.Second),
+		wakeup:    make(chan struct{}, 1),
+		closed:    make(chan struct{}),
+		threshold: int64(config.Get("prefetch", "threshold").Int(64)),
+	}
+
+	if subscriber.threshold > 0 {
+		subscriber.prefetcher = prefetch.NewPrefetcher(
+			subscriber.blockByNumber,
+			config.Get("prefetch", "workers").Int(8),
+			int64(config.Get("prefetch", "window").Int(64)),
+			int64(config.Get("prefetch", "memory").Int(64*1024*1024)),
+		)
+	}
+
+	return subscriber, nil
+}
+
+// Run subscribe and fetch blocks until closed
+func (subscriber *Subscriber) Run() {
+	go subscriber.fetchLoop()
+
+	for {
+		if err := subscriber.subscribe(); err != nil {
+			subscriber.WarnF("subscription err: %s, reconnect after %s", err, subscriber.reconnect)
+		}
+
+		// fill the gap while reconnecting
+		subscriber.wake()
+
+		select {
+		case <-subscriber.closed:
+			return
+		case <-time.After(subscriber.reconnect):
+		}
+	}
+}
+
+// Close stop the subscriber
+func (subscriber *Subscriber) Close() {
+	subscriber.Lock()
+	defer subscriber.Unlock()
+
+	select {
+	case <-subscriber.closed:
+		return
+	default:
+	}
+
+	close(subscriber.closed)
+
+	if subscriber.conn != nil {
+		subscriber.conn.Close()
+	}
+}
+
+// Rewind fetch blocks from block again
+func (subscriber *Subscriber) Rewind(block int64) error {
+	subscriber.Lock()
+	subscriber.next = block
+
+	// the rewound blocks are handled again
+	for number := range subscriber.hashes {
+		if number >= block {
+			delete(subscriber.hashes, number)
+		}
+	}
+
+	subscriber.Unlock()
+
+	subscriber.wake()
+
+	return nil
+}
+
+func (subscriber *Subscriber) wake() {
+	select {
+	case subscriber.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (subscriber *Subscriber) subscribe() error {
+	conn, _, err := websocket.DefaultDialer.Dial(subscriber.url, nil)
+
+	if err != nil {
+		return err
+	}
+
+	subscriber.Lock()
+
+	select {
+	case <-subscriber.closed:
+		subscriber.Unlock()
+		conn.Close()
+		return nil
+	default:
+	}
+
+	subscriber.conn = conn
+	subscriber.Unlock()
+
+	defer func() {
+		subscriber.Lock()
+		subscriber.conn = nil
+		subscriber.Unlock()
+
+		conn.Close()
+	}()
+
+	if err := conn.WriteJSON(request(1, "eth_subscribe", "newHeads")); err != nil {
+		return err
+	}
+
+	if subscriber.addresses != nil {
+		addresses, err := subscriber.addresses()
+
+		if err != nil {
+			return err
+		}
+
+		if len(addresses) > 0 {
+			filter := map[string]interface{}{"address": addresses}
+
+			if err := conn.WriteJSON(request(2, "eth_subscribe", "logs", filter)); err != nil {
+				return err
+			}
+		}
+	}
+
+	subscriber.InfoF("subscribed %s", subscriber.url)
+
+	// catch up the blocks mined while disconnected
+	subscriber.wake()
+
+	for {
+		var message struct {
+			ID     *int64           `json:"id"`
+			Method string           `json:"method"`
+			Error  *json.RawMessage `json:"error"`
+		}
+
+		if err := conn.ReadJSON(&message); err != nil {
+			select {
+			case <-subscriber.closed:
+				return nil
+			default:
+				return err
+			}
+		}
+
+		if message.Error != nil {
+			return fmt.Errorf("subscribe err: %s", string(*message.Error))
+		}
+
+		if message.Method == "eth_subscription" {
+			subscriber.wake()
+		}
+	}
+}
+
+func (subscriber *Subscriber) fetchLoop() {
+	ticker := time.NewTicker(subscriber.poll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-subscriber.closed:
+			return
+		case <-subscriber.wakeup:
+		case <-ticker.C:
+		}
+
+		if err := subscriber.fetch(); err != nil {
+			subscriber.WarnF("fetch blocks err: %s", err)
+		}
+	}
+}
+
+// fetch handle blocks from next to chain head
+func (subscriber *Subscriber) fetch() error {
+	head, err := subscriber.blockNumber()
+
+	if err != nil {
+		return err
+	}
+
+	if err := subscriber.resume(head); err != nil {
+		return err
+	}
+
+	for {
+		subscriber.Lock()
+
+		// drop the blocks fetched by the stopped prefetching
+		subscriber.parents = make(map[string]string)
+
+		next := subscriber.next
+
+		subscriber.Unlock()
+
+		if next > head {
+			return nil
+		}
+
+		if subscriber.prefetcher != nil && head-next >= subscriber.threshold {
+			subscriber.InfoF("catch up blocks [%d,%d] with prefetching", next, head)
+
+			stopped, err := subscriber.prefetcher.Run(next, head, subscriber.handle)
+
+			if err == errRewound {
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+
+			// the node not reach the block yet
+			if stopped <= head {
+				return nil
+			}
+
+			// fetch the blocks mined while catching up
+			if head, err = subscriber.blockNumber(); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		block, _, err := subscriber.blockByNumber(next)
+
+		if err != nil {
+			return err
+		}
+
+		if block == nil {
+			return nil
+		}
+
+		if err := subscriber.handle(block); err != nil && err != errRewound {
+			return err
+		}
+	}
+}
+
+// resume start from the block after the cursor if the next block not set, or from the chain head if no block processed
+func (subscriber *Subscriber) resume(head int64) error {
+	subscriber.Lock()
+	resumed := subscriber.next >= 0
+	subscriber.Unlock()
+
+	if resumed {
+		return nil
+	}
+
+	block, hash := int64(-1), ""
+
+	if subscriber.cursor != nil {
+		var err error
+
+		if block, hash, err = subscriber.cursor(); err != nil {
+			return err
+		}
+	}
+
+	subscriber.Lock()
+	defer subscriber.Unlock()
+
+	// rewound while loading the cursor
+	if subscriber.next >= 0 {
+		return nil
+	}
+
+	if block < 0 {
+		subscriber.next = head
+		return nil
+	}
+
+	subscriber.InfoF("resume from block %d after cursor %s", block+1, hash)
+
+	subscriber.next = block + 1
+
+	// the cursor rewound offline has no hash
+	if hash != "" {
+		subscriber.hashes[block] = hash
+	}
+
+	return nil
+}
+
+// handle the next block, and move to the next one if not rewound while handling,
+// step back to the parent block if the block not follow the handled parent
+func (subscriber *Subscriber) handle(block *rpc.Block) error {
+	number, err := strconv.ParseInt(strings.TrimPrefix(block.Number, "0x"), 16, 64)
+
+	if err != nil {
+		return err
+	}
+
+	subscriber.Lock()
+
+	rewound := subscriber.next != number
+
+	parent, fetched := subscriber.parents[block.Hash]
+	delete(subscriber.parents, block.Hash)
+
+	handled, ok := subscriber.hashes[number-1]
+
+	if !rewound && fetched && ok && parent != handled {
+		delete(subscriber.hashes, number-1)
+		subscriber.next = number - 1
+		subscriber.Unlock()
+
+		subscriber.WarnF("block %d parent %s not follow the handled block %s, refetch the reorged block", number, parent, handled)
+
+		return errRewound
+	}
+
+	subscriber.Unlock()
+
+	if rewound {
+		return errRewound
+	}
+
+	if err := subscriber.handler(block); err != nil {
+		return err
+	}
+
+	subscriber.Lock()
+
+	// keep the rewind target set while handling
+	if subscriber.next == number {
+		subscriber.next = number + 1
+		subscriber.hashes[number] = block.Hash
+
+		delete(subscriber.hashes, number-subscriber.depth)
+	}
+
+	subscriber.Unlock()
+
+	return nil
+}
+
+func (subscriber *Subscriber) blockNumber() (int64, error) {
+	var result string
+
+	if _, err := subscriber.call("eth_blockNumber", &result); err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(strings.TrimPrefix(result, "0x"), 16, 64)
+}
+
+// blockByNumber get block with full txs, the size is the response json length
+func (subscriber *Subscriber) blockByNumber(number int64) (*rpc.Block, int64, error) {
+	var raw json.RawMessage
+
+	size, err := subscriber.call("eth_getBlockByNumber", &raw, "0x"+strconv.FormatInt(number, 16), true)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var block *rpc.Block
+
+	if err := json.Unmarshal(raw, &block); err != nil || block == nil {
+		return nil, size, err
+	}
+
+	var header struct {
+		ParentHash string `json:"parentHash"`
+	}
+
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, size, err
+	}
+
+	subscriber.Lock()
+	subscriber.parents[block.Hash] = header.ParentHash
+	subscriber.Unlock()
+
+	return block, size, nil
+}
+
+func (subscriber *Subscriber) call(method string, result interface{}, params ...interface{}) (size int64, err error) {
+	start := time.Now()
+
+	defer func() {
+		metrics.ObserveRPC("subscriber", subscriber.chain, method, start, err)
+	}()
+
+	return rpcpool.Call(context.Background(), subscriber.client, subscriber.ethnode, method, result, params...)
+}
+
+func request(id int64, method string, params ...interface{}) map[string]interface{} {
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	}
+}